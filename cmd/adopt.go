@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/pkg/config"
+)
+
+// runAdopt implements `pfinance adopt -email EMAIL`, which gives the assets, returns,
+// snapshots, targets and benchmarks recorded before accounts existed to one user.
+func runAdopt(ctx context.Context, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("adopt", flag.ContinueOnError)
+	email := flags.String("email", "", "email of the registered user who takes over the rows without an owner")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	db, err := config.ConnectDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	users := repositories.NewUserRepository(db)
+	user, err := users.GetUserByEmail(ctx, *email)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no user registered as %q", *email)
+	}
+	if err != nil {
+		return err
+	}
+
+	adopted, err := users.AdoptOwnerless(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	logger.Info("Adopted rows without an owner", "user", user.Email, "rows", adopted)
+	return nil
+}
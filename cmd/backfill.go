@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/internal/services"
+	"github.com/jagac/pfinance/pkg/cache"
+	"github.com/jagac/pfinance/pkg/config"
+	"github.com/jagac/pfinance/pkg/worker"
+)
+
+// runBackfill implements `pfinance backfill -from YYYY-MM-DD [-to YYYY-MM-DD] [-asset ID]`.
+func runBackfill(ctx context.Context, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	assetID := flags.Int("asset", 0, "ID of the asset to backfill, all assets when omitted")
+	fromFlag := flags.String("from", "", "first day to backfill (YYYY-MM-DD)")
+	toFlag := flags.String("to", time.Now().Format(time.DateOnly), "last day to backfill (YYYY-MM-DD)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	from, err := time.Parse(time.DateOnly, *fromFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -from date %q", *fromFlag)
+	}
+	to, err := time.Parse(time.DateOnly, *toFlag)
+	if err != nil {
+		return fmt.Errorf("invalid -to date %q", *toFlag)
+	}
+
+	cfg := config.LoadConfig()
+	lotMethod, err := services.ParseLotMethod(cfg.LotMethod)
+	if err != nil {
+		return err
+	}
+
+	db, err := config.ConnectDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cache := cache.NewCache[string, worker.TaskResult]()
+	converter := services.NewCurrencyConverter(cfg.BaseCurrency, cache, services.NewFrankfurterFetcher())
+
+	repo := repositories.NewAssetRepository(db)
+	assetService := services.NewAssetService(repo, repositories.NewTransactionRepository(db),
+		repositories.NewInterestRateRepository(db), repositories.NewPortfolioRepository(db), lotMethod)
+	backfiller := services.NewBackfiller(repo, assetService, repositories.NewAssetReturnHistoryRepository(db),
+		repositories.NewPriceRepository(db), repositories.NewSnapshotRepository(db), converter, newPriceRegistry())
+
+	written, err := backfiller.Backfill(ctx, *assetID, from, to)
+	logger.Info("Backfill finished", "asset", *assetID, "from", from, "to", to, "written", written)
+	return err
+}
@@ -2,10 +2,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -14,14 +19,61 @@ import (
 	"github.com/jagac/pfinance/internal/jobs"
 	"github.com/jagac/pfinance/internal/middleware"
 	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/internal/repositories/migrations"
 	"github.com/jagac/pfinance/internal/routes"
 	"github.com/jagac/pfinance/internal/services"
 	"github.com/jagac/pfinance/pkg/cache"
 	"github.com/jagac/pfinance/pkg/config"
 	"github.com/jagac/pfinance/pkg/logger"
+	"github.com/jagac/pfinance/pkg/migrate"
+	"github.com/jagac/pfinance/pkg/notification"
 	"github.com/jagac/pfinance/pkg/worker"
 )
 
+// newPriceRegistry registers the price sources of each asset type, primary source first.
+func newPriceRegistry() *services.PriceRegistry {
+	prices := services.NewPriceRegistry()
+	prices.Register("Stock", services.NewStockFetcher(config.LoadConfig().StockAPIURL), services.NewStooqStockFetcher())
+	prices.Register("Gold", services.NewGoldFetcher(), services.NewStooqGoldFetcher())
+	prices.Register("Crypto", services.NewCryptoFetcher())
+	return prices
+}
+
+// newCORSConfig reads the CORS policy from the config. Lists are comma separated.
+func newCORSConfig(cfg config.GlobalConfig) (middleware.CORSConfig, error) {
+	list := func(value string) []string {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	credentials, err := strconv.ParseBool(cfg.CORSCredentials)
+	if err != nil {
+		return middleware.CORSConfig{}, fmt.Errorf("CORS_ALLOW_CREDENTIALS: %w", err)
+	}
+	origins := list(cfg.CORSOrigins)
+	// Echoing any origin with credentials would let every site call the API as the user
+	if credentials && slices.Contains(origins, "*") {
+		return middleware.CORSConfig{}, errors.New("CORS_ALLOWED_ORIGINS must list origins when CORS_ALLOW_CREDENTIALS is true")
+	}
+	maxAge, err := strconv.Atoi(cfg.CORSMaxAge)
+	if err != nil {
+		return middleware.CORSConfig{}, fmt.Errorf("CORS_MAX_AGE: %w", err)
+	}
+
+	return middleware.CORSConfig{
+		AllowedOrigins:   origins,
+		AllowedMethods:   list(cfg.CORSMethods),
+		AllowedHeaders:   list(cfg.CORSHeaders),
+		AllowCredentials: credentials,
+		MaxAge:           maxAge,
+	}, nil
+}
+
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -31,6 +83,27 @@ func main() {
 		panic(err)
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(ctx, logger, os.Args[2:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(ctx, logger, os.Args[2:]); err != nil {
+			log.Fatalf("Backfill failed: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "adopt" {
+		if err := runAdopt(ctx, logger, os.Args[2:]); err != nil {
+			log.Fatalf("Adopt failed: %v", err)
+		}
+		return
+	}
+
 	mux := http.NewServeMux()
 
 	cache := cache.NewCache[string, worker.TaskResult]()
@@ -41,23 +114,96 @@ func main() {
 		log.Fatalf("Error connecting to the database: %v", err)
 	}
 	defer db.Close()
+
+	migrator, err := migrate.New(db, logger, migrations.FS)
+	if err != nil {
+		log.Fatalf("Error loading migrations: %v", err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		log.Fatalf("Error applying migrations: %v", err)
+	}
 	go worker1.Run("Worker")
 
 	loggingConfig := middleware.LoggingConfig{Logger: logger}
-	corsConfig := middleware.CORSConfig{}
+	corsConfig, err := newCORSConfig(config.LoadConfig())
+	if err != nil {
+		log.Fatalf("Invalid CORS config: %v", err)
+	}
 	logMiddleware := loggingConfig.Middleware
 	corsMiddleware := corsConfig.Middleware
-	stockFetcher := services.NewStockFetcher()
-	goldFetcher := services.NewGoldFetcher()
+	sessionTTL, err := time.ParseDuration(config.LoadConfig().SessionTTL)
+	if err != nil {
+		log.Fatalf("Invalid session TTL: %v", err)
+	}
+	authService := services.NewAuthService(repositories.NewUserRepository(db), sessionTTL)
+	authConfig := middleware.AuthConfig{Auth: authService}
+	apiKeyService := services.NewAPIKeyService(repositories.NewAPIKeyRepository(db))
+	apiKeyConfig := middleware.APIKeyConfig{Keys: apiKeyService}
+	// Routes take either an API key or a session token and can be narrowed to one
+	// portfolio with ?portfolio=
+	authMiddleware := func(next http.Handler) http.Handler {
+		return apiKeyConfig.Middleware(authConfig.Middleware(middleware.PortfolioFilter(next)))
+	}
+	prices := newPriceRegistry()
+	rateFetcher := services.NewFrankfurterFetcher()
+	baseCurrency := config.LoadConfig().BaseCurrency
+	converter := services.NewCurrencyConverter(baseCurrency, cache, rateFetcher)
 
 	repo := repositories.NewAssetRepository(db)
 	newRepo := repositories.NewAssetReturnHistoryRepository(db)
-	assetService := services.NewAssetService(repo)
-	returnService := services.NewHistoricReturns(repo, newRepo, goldFetcher, stockFetcher)
-	returnCalc := services.NewReturnsCalculator(repo, cache, newRepo)
+	txRepo := repositories.NewTransactionRepository(db)
+	priceRepo := repositories.NewPriceRepository(db)
+	portfolioRepo := repositories.NewPortfolioRepository(db)
+	lotMethod, err := services.ParseLotMethod(config.LoadConfig().LotMethod)
+	if err != nil {
+		log.Fatalf("Invalid lot method: %v", err)
+	}
+	assetService := services.NewAssetService(repo, txRepo, repositories.NewInterestRateRepository(db),
+		portfolioRepo, lotMethod)
+	snapshotRepo := repositories.NewSnapshotRepository(db)
+	returnService := services.NewHistoricReturns(repo, assetService, newRepo, converter, prices, priceRepo,
+		snapshotRepo)
+	returnCalc := services.NewReturnsCalculator(repo, assetService, converter, cache, newRepo, priceRepo)
+	backfiller := services.NewBackfiller(repo, assetService, newRepo, priceRepo, snapshotRepo, converter, prices)
+	// Preflight requests match no route, as routes are registered per method
+	mux.Handle("OPTIONS /", corsMiddleware(http.NotFoundHandler()))
+	authRouter := routes.NewAuthRouter(handlers.NewAuthHandler(authService, strings.HasPrefix(config.LoadConfig().PublicHost, "https://")), logMiddleware, corsMiddleware, authConfig.Middleware)
+	authRouter.RegisterRoutes(mux)
+	apiKeyRouter := routes.NewAPIKeyRouter(handlers.NewAPIKeyHandler(apiKeyService), logMiddleware, corsMiddleware, authConfig.Middleware)
+	apiKeyRouter.RegisterRoutes(mux)
+	portfolioService := services.NewPortfolioService(portfolioRepo, lotMethod)
+	portfolioRouter := routes.NewPortfolioRouter(handlers.NewPortfolioHandler(portfolioService), logMiddleware, corsMiddleware, authMiddleware)
+	portfolioRouter.RegisterRoutes(mux)
 	handler := handlers.NewAssetHandler(assetService, returnCalc, returnService)
-	assetRouter := routes.NewAssetRouter(handler, logMiddleware, corsMiddleware)
+	assetRouter := routes.NewAssetRouter(handler, logMiddleware, corsMiddleware, authMiddleware)
 	assetRouter.RegisterRoutes(mux)
+	performanceService := services.NewPerformanceService(repo, assetService, newRepo, converter)
+	performanceRouter := routes.NewPerformanceRouter(handlers.NewPerformanceHandler(performanceService), logMiddleware, corsMiddleware, authMiddleware)
+	performanceRouter.RegisterRoutes(mux)
+	riskFreeRate, err := strconv.ParseFloat(config.LoadConfig().RiskFreeRate, 64)
+	if err != nil {
+		log.Fatalf("Invalid risk-free rate: %v", err)
+	}
+	riskService := services.NewRiskService(performanceService, priceRepo, prices, riskFreeRate)
+	riskRouter := routes.NewRiskRouter(handlers.NewRiskHandler(riskService), logMiddleware, corsMiddleware, authMiddleware)
+	riskRouter.RegisterRoutes(mux)
+	benchmarkRepo := repositories.NewBenchmarkRepository(db)
+	benchmarkService := services.NewBenchmarkService(benchmarkRepo, performanceService, priceRepo, prices)
+	benchmarkRouter := routes.NewBenchmarkRouter(handlers.NewBenchmarkHandler(benchmarkService), logMiddleware, corsMiddleware, authMiddleware)
+	benchmarkRouter.RegisterRoutes(mux)
+	targetService := services.NewTargetService(repositories.NewTargetRepository(db), repo, returnCalc)
+	targetRouter := routes.NewTargetRouter(handlers.NewTargetHandler(targetService), logMiddleware, corsMiddleware, authMiddleware)
+	targetRouter.RegisterRoutes(mux)
+	dividendService := services.NewDividendService(repo, assetService, converter, prices)
+	dividendRouter := routes.NewDividendRouter(handlers.NewDividendHandler(dividendService), logMiddleware, corsMiddleware, authMiddleware)
+	dividendRouter.RegisterRoutes(mux)
+	cfg := config.LoadConfig()
+	// Alerts are only evaluated when NOTIFIER names a way to send them ("email" or "matrix")
+	notifier := notification.NotifierFactory(cfg.Notifier, &cfg)
+	alertService := services.NewAlertService(repositories.NewAlertRepository(db), repositories.NewUserRepository(db), repo, portfolioRepo, snapshotRepo,
+		returnCalc, performanceService, targetService, notifier)
+	alertRouter := routes.NewAlertRouter(handlers.NewAlertHandler(alertService), logMiddleware, corsMiddleware, authMiddleware)
+	alertRouter.RegisterRoutes(mux)
 
 	hourlyTicker := time.NewTicker(31 * time.Minute)
 	defer hourlyTicker.Stop()
@@ -67,32 +213,80 @@ func main() {
 	goldTask := worker.Task{
 		OriginContext: context.Background(),
 		Name:          "goldPrice",
-		Job:           jobs.FetchGoldJob(goldFetcher),
+		Job:           jobs.FetchGoldJob(prices, priceRepo, baseCurrency),
 		TTL:           29 * time.Minute,
 	}
 
 	stockTask := worker.Task{
 		OriginContext: context.Background(),
 		Name:          "stockPrice",
-		Job:           jobs.FetchStocksJob(repo, stockFetcher),
+		Job:           jobs.FetchStocksJob(repo, prices, priceRepo, baseCurrency),
+		TTL:           29 * time.Minute,
+	}
+
+	cryptoTask := worker.Task{
+		OriginContext: context.Background(),
+		Name:          "cryptoPrice",
+		Job:           jobs.FetchCryptoJob(repo, prices, priceRepo, baseCurrency),
+		TTL:           29 * time.Minute,
+	}
+
+	benchmarkTask := worker.Task{
+		OriginContext: context.Background(),
+		Name:          "benchmarkPrice",
+		Job:           jobs.FetchBenchmarksJob(benchmarkRepo, prices, priceRepo, baseCurrency),
+		TTL:           29 * time.Minute,
+	}
+
+	fxTask := worker.Task{
+		OriginContext: context.Background(),
+		Name:          "fxRates",
+		Job:           jobs.FetchFXJob(rateFetcher, baseCurrency),
+		TTL:           29 * time.Minute,
+	}
+
+	alertTask := worker.Task{
+		OriginContext: context.Background(),
+		Name:          "alerts",
+		Job:           jobs.EvaluateAlertsJob(alertService),
 		TTL:           29 * time.Minute,
 	}
 
 	dailyReturnTask := worker.Task{
 		OriginContext: context.Background(),
 		Name:          "dailyReturn",
-		Job:           jobs.TotalReturnsJob(returnService),
+		Job:           jobs.TotalReturnsJob(returnService, backfiller, repositories.NewJobRunRepository(db)),
 		TTL:           10 * time.Minute,
 	}
 
 	go func() {
 		for range hourlyTicker.C {
+			worker1.Enqueue(fxTask)
 			worker1.Enqueue(goldTask)
 			worker1.Enqueue(stockTask)
+			worker1.Enqueue(benchmarkTask)
+			worker1.Enqueue(cryptoTask)
+			// Queued last so the rules see the prices just fetched
+			if notifier != nil {
+				worker1.Enqueue(alertTask)
+			}
 		}
 	}()
+	dividendTask := worker.Task{
+		OriginContext: context.Background(),
+		Name:          "dividends",
+		Job:           jobs.FetchDividendsJob(dividendService),
+		TTL:           10 * time.Minute,
+	}
+	fetchDividends := config.LoadConfig().FetchDividends == "true"
+
 	go func() {
+		// Run once at startup so days missed while the service was down are caught up.
+		worker1.Enqueue(dailyReturnTask)
 		for range dailyTicker.C {
+			if fetchDividends {
+				worker1.Enqueue(dividendTask)
+			}
 			worker1.Enqueue(dailyReturnTask)
 		}
 	}()
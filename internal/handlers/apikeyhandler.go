@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type APIKeyHandler struct {
+	Service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(s *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{Service: s}
+}
+
+// CreateKey issues a key; the response is the only time the key itself is shown.
+func (h *APIKeyHandler) CreateKey(w http.ResponseWriter, r *http.Request) {
+	var key models.APIKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.CreateKey(r.Context(), &key); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+func (h *APIKeyHandler) GetKeys(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.Service.Repo.GetAllKeys(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if keys == nil {
+		keys = []*models.APIKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+func (h *APIKeyHandler) DeleteKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteKey(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
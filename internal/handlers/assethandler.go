@@ -1,10 +1,13 @@
 package handlers
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/jagac/pfinance/internal/models"
 	"github.com/jagac/pfinance/internal/services"
@@ -28,7 +31,7 @@ func (h *AssetHandler) CreateAsset(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.Service.CreateAsset(r.Context(), &asset); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		assetError(w, err)
 		return
 	}
 
@@ -55,14 +58,14 @@ func (h *AssetHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
 	var mu sync.Mutex
 
 	returns := make(map[int]float32)
-	errorChan := make(chan error, 3)
+	errorChan := make(chan error, 5)
 
-	wg.Add(3)
+	wg.Add(5)
 
 	// Fetch stock returns
 	go func() {
 		defer wg.Done()
-		stockReturns, err := h.ReturnCalculator.StockReturns()
+		stockReturns, err := h.ReturnCalculator.StockReturns(r.Context())
 		if err != nil {
 			errorChan <- err
 			return
@@ -77,7 +80,7 @@ func (h *AssetHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
 	// Fetch interest returns
 	go func() {
 		defer wg.Done()
-		interestPL, err := h.ReturnCalculator.CalculateInterestPL()
+		interestPL, err := h.ReturnCalculator.CalculateInterestPL(r.Context())
 		if err != nil {
 			errorChan <- err
 			return
@@ -92,7 +95,7 @@ func (h *AssetHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
 	// Fetch gold returns
 	go func() {
 		defer wg.Done()
-		goldReturns, err := h.ReturnCalculator.GoldReturns()
+		goldReturns, err := h.ReturnCalculator.GoldReturns(r.Context())
 		if err != nil {
 			errorChan <- err
 			return
@@ -104,6 +107,36 @@ func (h *AssetHandler) GetReturns(w http.ResponseWriter, r *http.Request) {
 		mu.Unlock()
 	}()
 
+	// Fetch crypto returns
+	go func() {
+		defer wg.Done()
+		cryptoReturns, err := h.ReturnCalculator.CryptoReturns(r.Context())
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		mu.Lock()
+		for id, value := range cryptoReturns {
+			returns[id] = value
+		}
+		mu.Unlock()
+	}()
+
+	// Fetch bond returns
+	go func() {
+		defer wg.Done()
+		bondReturns, err := h.ReturnCalculator.BondReturns(r.Context())
+		if err != nil {
+			errorChan <- err
+			return
+		}
+		mu.Lock()
+		for id, value := range bondReturns {
+			returns[id] = value
+		}
+		mu.Unlock()
+	}()
+
 	go func() {
 		wg.Wait()
 		close(errorChan)
@@ -134,20 +167,338 @@ func (h *AssetHandler) GetAsset(w http.ResponseWriter, r *http.Request) {
 
 	asset, err := h.Service.GetAsset(r.Context(), id)
 	if err != nil {
-		http.Error(w, "Asset not found", http.StatusNotFound)
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(asset)
+}
+
+func (h *AssetHandler) UpdateAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var asset models.Asset
+	if err := json.NewDecoder(r.Body).Decode(&asset); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	asset.ID = id
+
+	h.saveAsset(w, r, &asset)
+}
+
+// PatchAsset applies only the fields present in the request body on top of the stored asset.
+func (h *AssetHandler) PatchAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.Service.GetAsset(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(asset); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	asset.ID = id
+
+	h.saveAsset(w, r, asset)
+}
+
+func (h *AssetHandler) saveAsset(w http.ResponseWriter, r *http.Request, asset *models.Asset) {
+	if err := h.Service.UpdateAsset(r.Context(), asset); err != nil {
+		assetError(w, err)
 		return
 	}
 
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(asset)
 }
 
-func (h *AssetHandler) GetMonthlyReturns(w http.ResponseWriter, r *http.Request) {
-	returns, err := h.ReturnCalculator.GetMonthlyReturns()
+// assetError answers a request that failed in the asset service: 400 for invalid input,
+// 404 for an asset that does not exist or belongs to someone else, 500 otherwise.
+func assetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAsset):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Asset not found", http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (h *AssetHandler) DeleteAsset(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteAsset(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AssetHandler) AddTransaction(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var tx models.Transaction
+	if err := json.NewDecoder(r.Body).Decode(&tx); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tx.AssetID = id
+	if tx.Date.IsZero() {
+		tx.Date = time.Now()
+	}
+
+	if err := h.Service.AddTransaction(r.Context(), &tx); err != nil {
+		assetError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tx)
+}
+
+func (h *AssetHandler) GetTransactions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	txs, err := h.Service.GetTransactions(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(txs)
+}
+
+func (h *AssetHandler) AddInterestRate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var rate models.InterestRate
+	if err := json.NewDecoder(r.Body).Decode(&rate); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rate.AssetID = id
+
+	if err := h.Service.AddInterestRate(r.Context(), &rate); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Asset not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rate)
+}
+
+func (h *AssetHandler) GetInterestRates(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	rates, err := h.Service.GetInterestRates(r.Context(), id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rates == nil {
+		rates = []models.InterestRate{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+func (h *AssetHandler) DeleteInterestRate(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+	rateID, err := strconv.Atoi(r.PathValue("rateId"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteInterestRate(r.Context(), id, rateID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Rate not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AssetHandler) GetHolding(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.Service.GetAsset(r.Context(), id)
+	if err != nil {
+		assetError(w, err)
+		return
+	}
+
+	holding, err := h.Service.GetHolding(r.Context(), asset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(holding)
+}
+
+func (h *AssetHandler) GetProfitAndLoss(w http.ResponseWriter, r *http.Request) {
+	pnl, err := h.ReturnCalculator.ProfitAndLoss(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pnl)
+}
+
+func (h *AssetHandler) GetValuations(w http.ResponseWriter, r *http.Request) {
+	valuations, err := h.ReturnCalculator.Valuations(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(valuations)
+}
+
+func (h *AssetHandler) GetPortfolioSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.ReturnCalculator.Summary(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// GetSnapshots returns the portfolio total, or with ?asset= one asset, over ?from=&to=,
+// downsampled to ?interval=daily, weekly or monthly.
+func (h *AssetHandler) GetSnapshots(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var assetID *int
+	if v := r.URL.Query().Get("asset"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid asset ID", http.StatusBadRequest)
+			return
+		}
+		assetID = &id
+	}
+
+	snapshots, err := h.ReturnService.Snapshots(r.Context(), assetID, from, to, r.URL.Query().Get("interval"))
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownInterval) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(snapshots)
+}
+
+func (h *AssetHandler) GetBondValuation(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	asset, err := h.Service.GetAsset(r.Context(), id)
+	if err != nil {
+		assetError(w, err)
+		return
+	}
+	if asset.Type != "Bond" {
+		http.Error(w, "Bond not found", http.StatusNotFound)
+		return
+	}
+
+	holding, err := h.Service.GetHolding(r.Context(), asset)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	valuation, err := services.ValueBond(asset, holding, time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(returns)
+	json.NewEncoder(w).Encode(valuation)
 }
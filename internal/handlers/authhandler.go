@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/jagac/pfinance/internal/middleware"
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type AuthHandler struct {
+	Service *services.AuthService
+	// SecureCookies marks the session cookie Secure, for deployments served over HTTPS
+	SecureCookies bool
+}
+
+func NewAuthHandler(s *services.AuthService, secureCookies bool) *AuthHandler {
+	return &AuthHandler{Service: s, SecureCookies: secureCookies}
+}
+
+func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var creds models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.Service.Register(r.Context(), creds)
+	if err != nil {
+		if errors.Is(err, services.ErrEmailTaken) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(user)
+}
+
+func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	var creds models.Credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	session, err := h.Service.Login(r.Context(), creds)
+	if errors.Is(err, services.ErrInvalidCredentials) {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Browsers keep the token in a cookie scripts cannot read; other clients use the body
+	http.SetCookie(w, h.sessionCookie(session.Token, session.ExpiresAt))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(session)
+}
+
+// Logout ends the session the request was authenticated with and clears the session cookie.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	token, _ := middleware.SessionToken(r)
+	if err := h.Service.Logout(r.Context(), token); err != nil && !errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	http.SetCookie(w, h.sessionCookie("", time.Unix(0, 0)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sessionCookie holds a session token. SameSite=Lax keeps other sites from sending it,
+// while a frontend on another subdomain of the same site still can.
+func (h *AuthHandler) sessionCookie(token string, expires time.Time) *http.Cookie {
+	return &http.Cookie{
+		Name:     middleware.SessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.SecureCookies,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// GetMe returns the logged in user.
+func (h *AuthHandler) GetMe(w http.ResponseWriter, r *http.Request) {
+	token, _ := middleware.SessionToken(r)
+	user, err := h.Service.Authenticate(r.Context(), token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type BenchmarkHandler struct {
+	Service *services.BenchmarkService
+}
+
+func NewBenchmarkHandler(s *services.BenchmarkService) *BenchmarkHandler {
+	return &BenchmarkHandler{Service: s}
+}
+
+func (h *BenchmarkHandler) CreateBenchmark(w http.ResponseWriter, r *http.Request) {
+	var benchmark models.Benchmark
+	if err := json.NewDecoder(r.Body).Decode(&benchmark); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.CreateBenchmark(r.Context(), &benchmark); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(benchmark)
+}
+
+func (h *BenchmarkHandler) GetBenchmarks(w http.ResponseWriter, r *http.Request) {
+	benchmarks, err := h.Service.Repo.GetAllBenchmarks(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(benchmarks)
+}
+
+func (h *BenchmarkHandler) DeleteBenchmark(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteBenchmark(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Benchmark not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// CompareBenchmarks returns the portfolio and benchmark cumulative returns over ?from=&to=.
+func (h *BenchmarkHandler) CompareBenchmarks(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	comparison, err := h.Service.Compare(r.Context(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(comparison)
+}
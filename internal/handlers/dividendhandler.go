@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type DividendHandler struct {
+	Service *services.DividendService
+}
+
+func NewDividendHandler(s *services.DividendService) *DividendHandler {
+	return &DividendHandler{Service: s}
+}
+
+// GetDividends reports the dividend income over ?from=&to= or ?range=.
+func (h *DividendHandler) GetDividends(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Service.Report(r.Context(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
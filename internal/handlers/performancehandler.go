@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type PerformanceHandler struct {
+	Service *services.PerformanceService
+}
+
+func NewPerformanceHandler(s *services.PerformanceService) *PerformanceHandler {
+	return &PerformanceHandler{Service: s}
+}
+
+func (h *PerformanceHandler) GetPerformance(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.Service.Performance(r.Context(), from, to)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// GetReturnHistory returns the returns per ?granularity= period (monthly by default) over
+// ?from=&to= or ?range=, optionally only for one ?asset= or asset ?type=.
+func (h *PerformanceHandler) GetReturnHistory(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := repositories.ReturnFilter{From: from, To: to, AssetType: query.Get("type")}
+	if v := query.Get("asset"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid asset ID", http.StatusBadRequest)
+			return
+		}
+		filter.AssetID = &id
+	}
+
+	granularity := query.Get("granularity")
+	if granularity == "" {
+		granularity = "monthly"
+	}
+
+	history, err := h.Service.ReturnHistory(r.Context(), filter, granularity)
+	if err != nil {
+		if errors.Is(err, services.ErrUnknownInterval) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// dateRange reads the optional from and to query parameters (YYYY-MM-DD), or a range
+// ending at to: ytd, 1m, 3m, 6m, 1y, 3y, 5y or all. The range defaults to everything up to today.
+func dateRange(r *http.Request) (time.Time, time.Time, error) {
+	var from time.Time
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+
+	if v := r.URL.Query().Get("to"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return from, to, errors.New("invalid to date, expected YYYY-MM-DD")
+		}
+		to = parsed
+	}
+	if v := r.URL.Query().Get("from"); v != "" {
+		parsed, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			return from, to, errors.New("invalid from date, expected YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	switch v := r.URL.Query().Get("range"); v {
+	case "", "all":
+	case "ytd":
+		from = time.Date(to.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	case "1m":
+		from = to.AddDate(0, -1, 0)
+	case "3m":
+		from = to.AddDate(0, -3, 0)
+	case "6m":
+		from = to.AddDate(0, -6, 0)
+	case "1y":
+		from = to.AddDate(-1, 0, 0)
+	case "3y":
+		from = to.AddDate(-3, 0, 0)
+	case "5y":
+		from = to.AddDate(-5, 0, 0)
+	default:
+		return from, to, fmt.Errorf("unknown range %q", v)
+	}
+
+	if to.Before(from) {
+		return from, to, errors.New("date range ends before it starts")
+	}
+	return from, to, nil
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type PortfolioHandler struct {
+	Service *services.PortfolioService
+}
+
+func NewPortfolioHandler(s *services.PortfolioService) *PortfolioHandler {
+	return &PortfolioHandler{Service: s}
+}
+
+func (h *PortfolioHandler) CreatePortfolio(w http.ResponseWriter, r *http.Request) {
+	var portfolio models.Portfolio
+	if err := json.NewDecoder(r.Body).Decode(&portfolio); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.CreatePortfolio(r.Context(), &portfolio); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(portfolio)
+}
+
+func (h *PortfolioHandler) GetPortfolios(w http.ResponseWriter, r *http.Request) {
+	portfolios, err := h.Service.Repo.GetAllPortfolios(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if portfolios == nil {
+		portfolios = []*models.Portfolio{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(portfolios)
+}
+
+func (h *PortfolioHandler) GetPortfolio(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	portfolio, err := h.Service.Repo.GetPortfolioByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Portfolio not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(portfolio)
+}
+
+func (h *PortfolioHandler) UpdatePortfolio(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var portfolio models.Portfolio
+	if err := json.NewDecoder(r.Body).Decode(&portfolio); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	portfolio.ID = id
+
+	if err := h.Service.UpdatePortfolio(r.Context(), &portfolio); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Portfolio not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(portfolio)
+}
+
+func (h *PortfolioHandler) DeletePortfolio(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeletePortfolio(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Portfolio not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, services.ErrPortfolioNotEmpty) {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type RiskHandler struct {
+	Service *services.RiskService
+}
+
+func NewRiskHandler(s *services.RiskService) *RiskHandler {
+	return &RiskHandler{Service: s}
+}
+
+// GetRisk reports the portfolio's risk over ?from=&to=. ?riskFree= overrides the configured
+// annual risk-free rate and ?benchmark= names the ticker beta is measured against.
+func (h *RiskHandler) GetRisk(w http.ResponseWriter, r *http.Request) {
+	from, to, err := dateRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	riskFreeRate := h.Service.RiskFreeRate
+	if v := r.URL.Query().Get("riskFree"); v != "" {
+		riskFreeRate, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "Invalid risk-free rate", http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := h.Service.Risk(r.Context(), from, to, riskFreeRate, r.URL.Query().Get("benchmark"))
+	if errors.Is(err, services.ErrInvalidTicker) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
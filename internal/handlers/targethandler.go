@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+type TargetHandler struct {
+	Service *services.TargetService
+}
+
+func NewTargetHandler(s *services.TargetService) *TargetHandler {
+	return &TargetHandler{Service: s}
+}
+
+func (h *TargetHandler) CreateTarget(w http.ResponseWriter, r *http.Request) {
+	var target models.Target
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.CreateTarget(r.Context(), &target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(target)
+}
+
+func (h *TargetHandler) GetTargets(w http.ResponseWriter, r *http.Request) {
+	targets, err := h.Service.Repo.GetAllTargets(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(targets)
+}
+
+func (h *TargetHandler) UpdateTarget(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	var target models.Target
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	target.ID = id
+
+	if err := h.Service.UpdateTarget(r.Context(), &target); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Target not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(target)
+}
+
+func (h *TargetHandler) DeleteTarget(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "Invalid ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.Service.DeleteTarget(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			http.Error(w, "Target not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetRebalance suggests trades for ?cash= of new money, leaving targets that drifted less
+// than ?threshold= percentage points alone. ?noSell=true only directs the new cash.
+func (h *TargetHandler) GetRebalance(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var cash, threshold float64
+	var noSell bool
+	var err error
+	if v := query.Get("cash"); v != "" {
+		if cash, err = strconv.ParseFloat(v, 64); err != nil || cash < 0 {
+			http.Error(w, "Invalid cash amount", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("threshold"); v != "" {
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil || threshold < 0 {
+			http.Error(w, "Invalid threshold", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("noSell"); v != "" {
+		if noSell, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "Invalid noSell flag", http.StatusBadRequest)
+			return
+		}
+	}
+
+	plan, err := h.Service.Rebalance(r.Context(), cash, threshold, noSell)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
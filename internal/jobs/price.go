@@ -2,50 +2,164 @@ package jobs
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/jagac/pfinance/internal/models"
 	"github.com/jagac/pfinance/internal/repositories"
 	"github.com/jagac/pfinance/internal/services"
 	"github.com/jagac/pfinance/pkg/worker"
 )
 
-// FetchGoldJob returns a worker job to fetch gold price
-func FetchGoldJob(fetcher *services.GoldFetcher) worker.Job {
+// dailyReturnJob is the name the daily returns job is recorded under in the job run ledger.
+const dailyReturnJob = "dailyReturn"
+
+// FetchGoldJob returns a worker job to fetch gold price in the given currency
+func FetchGoldJob(prices *services.PriceRegistry, priceRepository *repositories.PriceRepository, currency string) worker.Job {
+	return func(c context.Context) (any, error) {
+		quote, err := prices.Quote(c, "Gold", services.GoldSymbol, currency)
+		if err != nil {
+			return nil, err
+		}
+		if err := priceRepository.InsertQuote(c, "Gold", quote); err != nil {
+			return nil, err
+		}
+		return map[string]models.Quote{services.GoldSymbol: quote}, nil
+	}
+}
+
+func FetchStocksJob(assetRepository *repositories.AssetRepository, prices *services.PriceRegistry,
+	priceRepository *repositories.PriceRepository, currency string) worker.Job {
+	return fetchQuotesJob(assetRepository, prices, priceRepository, "Stock", currency)
+}
+
+// FetchCryptoJob returns a worker job to fetch the price of every crypto asset in the given currency
+func FetchCryptoJob(assetRepository *repositories.AssetRepository, prices *services.PriceRegistry,
+	priceRepository *repositories.PriceRepository, currency string) worker.Job {
+	return fetchQuotesJob(assetRepository, prices, priceRepository, "Crypto", currency)
+}
+
+// fetchQuotesJob fetches and persists a quote for the symbol of every asset of the given type,
+// keyed by that symbol as the valuations look it up.
+func fetchQuotesJob(assetRepository *repositories.AssetRepository, prices *services.PriceRegistry,
+	priceRepository *repositories.PriceRepository, assetType, currency string) worker.Job {
 	return func(c context.Context) (any, error) {
-		price, err := fetcher.FetchPrice("EUR")
+		assets, err := assetRepository.GetAssetsByType(c, assetType)
 		if err != nil {
 			return nil, err
 		}
-		return price, nil
+
+		tickerAndQuote := make(map[string]models.Quote)
+
+		for _, asset := range assets {
+			symbol := services.QuoteSymbol(asset)
+			if _, ok := tickerAndQuote[symbol]; ok {
+				continue
+			}
+			quote, err := prices.Quote(c, assetType, symbol, currency)
+			if err != nil {
+				return nil, err
+			}
+			if err := priceRepository.InsertQuote(c, assetType, quote); err != nil {
+				return nil, err
+			}
+			tickerAndQuote[symbol] = quote
+		}
+		return tickerAndQuote, nil
 	}
 }
 
-func FetchStocksJob(assetRepository *repositories.AssetRepository, fetcher *services.StockFetcher) worker.Job {
+// FetchBenchmarksJob returns a worker job to fetch and persist a quote for every benchmark ticker
+func FetchBenchmarksJob(benchmarkRepository *repositories.BenchmarkRepository, prices *services.PriceRegistry,
+	priceRepository *repositories.PriceRepository, currency string) worker.Job {
 	return func(c context.Context) (any, error) {
-		stocks, err := assetRepository.GetAssetsByType(c, "Stock")
+		benchmarks, err := benchmarkRepository.GetAllBenchmarks(c)
 		if err != nil {
 			return nil, err
 		}
 
-		tickerAndPrice := make(map[string]float32)
+		tickerAndQuote := make(map[string]models.Quote)
 
-		for _, stock := range stocks {
-			price, err := fetcher.FetchPrice(stock.Ticker)
+		for _, benchmark := range benchmarks {
+			// Users may compare against the same ticker
+			if _, ok := tickerAndQuote[benchmark.Ticker]; ok {
+				continue
+			}
+			quote, err := prices.Quote(c, "Stock", benchmark.Ticker, currency)
 			if err != nil {
 				return nil, err
 			}
-			tickerAndPrice[stock.Ticker] = price.Price
+			if err := priceRepository.InsertQuote(c, "Stock", quote); err != nil {
+				return nil, err
+			}
+			tickerAndQuote[benchmark.Ticker] = quote
 		}
-		return tickerAndPrice, nil
+		return tickerAndQuote, nil
 	}
 }
 
-func TotalReturnsJob(returnCalc *services.HistoricReturns) worker.Job {
+// FetchDividendsJob returns a worker job to book the dividends stocks paid since they were last recorded
+func FetchDividendsJob(dividends *services.DividendService) worker.Job {
 	return func(c context.Context) (any, error) {
-		err := returnCalc.Calc(c)
+		return nil, dividends.FetchDividends(c, time.Now())
+	}
+}
+
+// FetchFXJob returns a worker job to fetch exchange rates against the base currency
+func FetchFXJob(fetcher services.RateFetcher, base string) worker.Job {
+	return func(c context.Context) (any, error) {
+		rates, err := fetcher.FetchRates(base)
 		if err != nil {
 			return nil, err
 		}
-		return nil, nil
+		return rates, nil
+	}
+}
+
+// TotalReturnsJob returns a worker job recording today's returns in the job run ledger.
+// Days missed since the last run, e.g. while the service was down, are backfilled first.
+// The days are marked as run even when some asset failed, so an asset that keeps failing,
+// such as a delisted stock, does not make every later run refetch the whole range. Its
+// errors are returned, and its days can be filled in with `pfinance backfill -asset`.
+func TotalReturnsJob(returnCalc *services.HistoricReturns, backfiller *services.Backfiller,
+	jobRuns *repositories.JobRunRepository) worker.Job {
+	return func(c context.Context) (any, error) {
+		today := time.Now().UTC()
+
+		var errs []error
+		last, err := jobRuns.LastCompleted(c, dailyReturnJob)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+		case err != nil:
+			return nil, err
+		default:
+			from, to := last.AddDate(0, 0, 1), today.AddDate(0, 0, -1)
+			if !to.Before(from) {
+				if _, err := backfiller.Backfill(c, 0, from, to); err != nil {
+					errs = append(errs, err)
+				}
+				for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+					if err := jobRuns.MarkCompleted(c, dailyReturnJob, date); err != nil {
+						return nil, errors.Join(append(errs, err)...)
+					}
+				}
+			}
+		}
+
+		if err := returnCalc.Calc(c); err != nil {
+			errs = append(errs, err)
+		}
+		if err := jobRuns.MarkCompleted(c, dailyReturnJob, today); err != nil {
+			errs = append(errs, err)
+		}
+		return nil, errors.Join(errs...)
 	}
+}
 
+// EvaluateAlertsJob returns a worker job to check the alert rules against the prices just fetched
+func EvaluateAlertsJob(alerts *services.AlertService) worker.Job {
+	return func(c context.Context) (any, error) {
+		return nil, alerts.Evaluate(c, time.Now())
+	}
 }
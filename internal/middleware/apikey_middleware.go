@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+// APIKeyConfig is a configuration struct for the API key middleware.
+// It holds the service that resolves API keys to users and scopes.
+type APIKeyConfig struct {
+	Keys *services.APIKeyService // Service used to look up the key in the X-API-Key header
+}
+
+// Middleware authenticates requests that carry an "X-API-Key" header as the key's user,
+// allowing read-only keys nothing but GET and HEAD requests. Requests without the header
+// are passed on unchanged, to be authenticated by their session token.
+func (k *APIKeyConfig) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, scope, err := k.Keys.Authenticate(r.Context(), key)
+		if errors.Is(err, services.ErrUnauthenticated) {
+			http.Error(w, "invalid API key", http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if scope == models.APIKeyRead && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "API key is read-only", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(repositories.WithOwner(r.Context(), userID)))
+	})
+}
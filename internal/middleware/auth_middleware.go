@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/internal/services"
+)
+
+// SessionCookie is the cookie browsers keep their session token in.
+const SessionCookie = "pfinance_session"
+
+// AuthConfig is a configuration struct for the authentication middleware.
+// It holds the service that resolves session tokens to users.
+type AuthConfig struct {
+	Auth *services.AuthService // Service used to look up the session behind a bearer token
+}
+
+// Middleware rejects requests without a valid "Authorization: Bearer <token>" header or
+// session cookie and scopes the repository queries of the rest with the caller's user ID.
+// Requests already authenticated by an API key are passed on.
+//
+// A POST authenticated by the cookie alone must be sent as JSON. Browsers only send that
+// cross-origin after a CORS preflight, so other sites cannot post forms with the cookie.
+func (a *AuthConfig) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := repositories.OwnerFrom(r.Context()); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, ok := BearerToken(r)
+		if !ok {
+			token, ok = cookieToken(r)
+			if ok && r.Method == http.MethodPost && !isJSON(r) {
+				http.Error(w, "requests authenticated by cookie must be sent as JSON", http.StatusUnsupportedMediaType)
+				return
+			}
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, services.ErrUnauthenticated.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		user, err := a.Auth.Authenticate(r.Context(), token)
+		if errors.Is(err, services.ErrUnauthenticated) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(repositories.WithOwner(r.Context(), user.ID)))
+	})
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header.
+func BearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
+
+// SessionToken returns the session token of a request, from its Authorization header or
+// its session cookie.
+func SessionToken(r *http.Request) (string, bool) {
+	if token, ok := BearerToken(r); ok {
+		return token, true
+	}
+	return cookieToken(r)
+}
+
+func cookieToken(r *http.Request) (string, bool) {
+	cookie, err := r.Cookie(SessionCookie)
+	if err != nil || cookie.Value == "" {
+		return "", false
+	}
+	return cookie.Value, true
+}
+
+func isJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return err == nil && mediaType == "application/json"
+}
@@ -1,20 +1,72 @@
 package middleware
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
 
-type CORSConfig struct{}
+// CORSConfig is a configuration struct for the CORS middleware.
+// It decides which browser origins may call the API and with what.
+type CORSConfig struct {
+	AllowedOrigins   []string // Origins allowed to call the API: "*", "https://app.example.com" or "https://*.example.com"
+	AllowedMethods   []string // Methods a preflight request may ask for
+	AllowedHeaders   []string // Request headers a preflight request may ask for
+	AllowCredentials bool     // Whether browsers may send cookies and Authorization headers
+	MaxAge           int      // Seconds a preflight response may be cached, 0 to leave it to the browser
+}
 
+// Middleware answers preflight requests and adds the CORS headers to responses for allowed
+// origins. The matching origin is echoed back rather than "*", so credentials can be allowed.
 func (c *CORSConfig) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "POST, GET, PUT, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Add("Vary", "Origin")
+		origin := r.Header.Get("Origin")
+		allowed := origin != "" && c.allows(origin)
+
+		if allowed {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if c.AllowCredentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
 
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent) 
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+			if allowed {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(c.AllowedMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+				if c.MaxAge > 0 {
+					w.Header().Set("Access-Control-Max-Age", strconv.Itoa(c.MaxAge))
+				}
+			}
+			w.WriteHeader(http.StatusNoContent)
 			return
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// allows reports whether an origin matches one of the allowed origins. A "*" in place of
+// the first label of the host matches any subdomain, but not the domain itself.
+func (c *CORSConfig) allows(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, allowed := range c.AllowedOrigins {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" || allowed == origin {
+			return true
+		}
+
+		scheme, host, ok := strings.Cut(allowed, "://*.")
+		if !ok {
+			continue
+		}
+		rest, found := strings.CutPrefix(origin, scheme+"://")
+		if found && strings.HasSuffix(rest, "."+host) && !strings.Contains(strings.TrimSuffix(rest, "."+host), "/") {
+			return true
+		}
+	}
+	return false
+}
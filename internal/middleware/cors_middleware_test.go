@@ -0,0 +1,136 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSAllows(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		origin  string
+		want    bool
+	}{
+		{"exact origin", []string{"https://app.example.com"}, "https://app.example.com", true},
+		{"case is ignored", []string{"https://App.Example.com"}, "https://app.EXAMPLE.com", true},
+		{"another host", []string{"https://app.example.com"}, "https://evil.com", false},
+		{"another scheme", []string{"https://app.example.com"}, "http://app.example.com", false},
+		{"another port", []string{"https://app.example.com"}, "https://app.example.com:8443", false},
+		{"any origin", []string{"*"}, "https://evil.com", true},
+		{"wildcard subdomain", []string{"https://*.example.com"}, "https://app.example.com", true},
+		{"wildcard nested subdomain", []string{"https://*.example.com"}, "https://a.b.example.com", true},
+		{"wildcard skips the domain itself", []string{"https://*.example.com"}, "https://example.com", false},
+		{"wildcard needs a dot before the domain", []string{"https://*.example.com"}, "https://evilexample.com", false},
+		{"wildcard keeps the scheme", []string{"https://*.example.com"}, "http://app.example.com", false},
+		{"wildcard does not match a suffix in a path", []string{"https://*.example.com"}, "https://evil.com/.example.com", false},
+		{"second entry matches", []string{"https://a.com", "https://b.com"}, "https://b.com", true},
+		{"no origins", nil, "https://app.example.com", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &CORSConfig{AllowedOrigins: tt.allowed}
+			if got := c.allows(tt.origin); got != tt.want {
+				t.Errorf("allows(%q) with %q = %v, want %v", tt.origin, tt.allowed, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	c := &CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowCredentials: true,
+		MaxAge:           600,
+	}
+	handler := c.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		origin     string
+		preflight  bool
+		wantStatus int
+		wantHeader map[string]string
+	}{
+		{
+			name:       "allowed origin",
+			method:     http.MethodGet,
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusTeapot,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "https://app.example.com",
+				"Access-Control-Allow-Credentials": "true",
+				"Access-Control-Allow-Methods":     "",
+			},
+		},
+		{
+			name:       "other origin gets no headers",
+			method:     http.MethodGet,
+			origin:     "https://evil.com",
+			wantStatus: http.StatusTeapot,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":      "",
+				"Access-Control-Allow-Credentials": "",
+			},
+		},
+		{
+			name:       "preflight is answered",
+			method:     http.MethodOptions,
+			origin:     "https://app.example.com",
+			preflight:  true,
+			wantStatus: http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "https://app.example.com",
+				"Access-Control-Allow-Methods": "GET, POST",
+				"Access-Control-Allow-Headers": "Content-Type, Authorization",
+				"Access-Control-Max-Age":       "600",
+			},
+		},
+		{
+			name:       "preflight from other origin is answered without headers",
+			method:     http.MethodOptions,
+			origin:     "https://evil.com",
+			preflight:  true,
+			wantStatus: http.StatusNoContent,
+			wantHeader: map[string]string{
+				"Access-Control-Allow-Origin":  "",
+				"Access-Control-Allow-Methods": "",
+			},
+		},
+		{
+			name:       "options without a requested method is passed on",
+			method:     http.MethodOptions,
+			origin:     "https://app.example.com",
+			wantStatus: http.StatusTeapot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, "/api/assets", nil)
+			r.Header.Set("Origin", tt.origin)
+			if tt.preflight {
+				r.Header.Set("Access-Control-Request-Method", http.MethodPost)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			for name, want := range tt.wantHeader {
+				if got := w.Header().Get(name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package middleware
 
 // MiddlewareConfig holds the configuration for the different middlewares used in the application.
-// It includes configurations for CORS (Cross-Origin Resource Sharing) and Logging middleware.
+// It includes configurations for CORS (Cross-Origin Resource Sharing), Logging, Auth and API key middleware.
 type MiddlewareConfig struct {
 	CORSConfig    CORSConfig    // CORS configuration for handling cross-origin requests
 	LoggingConfig LoggingConfig // Logging configuration for logging HTTP request details
+	AuthConfig    AuthConfig    // Auth configuration for authenticating requests
+	APIKeyConfig  APIKeyConfig  // API key configuration for authenticating scripts
 }
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// PortfolioFilter narrows the repository queries of a request to the portfolio in its
+// ?portfolio= parameter. Requests without one aggregate across all of the caller's portfolios.
+func PortfolioFilter(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		param := r.URL.Query().Get("portfolio")
+		if param == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		portfolioID, err := strconv.Atoi(param)
+		if err != nil {
+			http.Error(w, "Invalid portfolio", http.StatusBadRequest)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(repositories.WithPortfolio(r.Context(), portfolioID)))
+	})
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+const (
+	AlertPriceAbove  = "price_above"
+	AlertPriceBelow  = "price_below"
+	AlertDailyChange = "daily_change"
+	AlertDrawdown    = "drawdown"
+	AlertDrift       = "drift"
+)
+
+// AlertRule notifies its owner when a condition starts to hold. Threshold is a price in the
+// asset's currency for price rules, and a percentage for daily change (up or down), drawdown
+// from the peak and drift of any target, in percentage points. A rule fires once when its
+// condition starts to hold, then not again until the condition has cleared and Cooldown
+// minutes have passed since the last notification. Alerts are sent to the owner's email,
+// which Recipient may repeat but not replace.
+type AlertRule struct {
+	ID              int        `json:"id"`
+	Kind            string     `json:"kind"`
+	AssetID         *int       `json:"assetId,omitempty"`
+	PortfolioID     *int       `json:"portfolioId,omitempty"`
+	Threshold       float64    `json:"threshold"`
+	Recipient       string     `json:"recipient,omitempty"`
+	CooldownMinutes int        `json:"cooldownMinutes"`
+	Disabled        bool       `json:"disabled"`
+	Active          bool       `json:"active"`
+	LastNotifiedAt  *time.Time `json:"lastNotifiedAt"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	OwnerID         int        `json:"-"`
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+const (
+	APIKeyRead  = "read"  // Only GET requests
+	APIKeyWrite = "write" // Every request a logged in user can make, except managing keys
+)
+
+// APIKey lets scripts call the API as a user, sent as an "X-API-Key" header. Key is only
+// set in the response that creates it; afterwards only its hash is stored.
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scope      string     `json:"scope"`
+	Key        string     `json:"key,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
@@ -14,5 +14,24 @@ type Asset struct {
 	InterestStart        time.Time `json:"interestStart,omitempty"`
 	InterestRate         float32   `json:"interestRate,omitempty"`
 	CompoundingFrequency string    `json:"compoundingFrequency,omitempty"`
+	DayCount             string    `json:"dayCount,omitempty"`
+	FaceValue            float32   `json:"faceValue,omitempty"`
+	CouponRate           float32   `json:"couponRate,omitempty"`
+	CouponFrequency      int       `json:"couponFrequency,omitempty"`
+	MaturityDate         time.Time `json:"maturityDate,omitempty"`
+	PurchasePrice        float32   `json:"purchasePrice,omitempty"`
+	PortfolioID          *int      `json:"portfolioId,omitempty"`
+	LotMethod            string    `json:"lotMethod,omitempty"` // Read from the asset's portfolio
+	OwnerID              *int      `json:"-"`
 	CreatedAt            time.Time
 }
+
+// InterestRate is the annual rate, in percent, a savings asset pays from EffectiveFrom
+// until the next rate change. Before its first change an asset pays its own InterestRate.
+type InterestRate struct {
+	ID            int       `json:"id"`
+	AssetID       int       `json:"assetId"`
+	Rate          float32   `json:"rate"`
+	EffectiveFrom time.Time `json:"effectiveFrom"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
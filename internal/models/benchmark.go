@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// Benchmark is an index ticker the portfolio is compared against.
+type Benchmark struct {
+	ID        int       `json:"id"`
+	Ticker    string    `json:"ticker"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CumulativeReturn is the growth of an investment from the start of a period up to Date.
+type CumulativeReturn struct {
+	Date   time.Time `json:"date"`
+	Return float64   `json:"return"`
+}
+
+// BenchmarkComparison sets the cumulative time-weighted return of the portfolio against
+// the cumulative return of each benchmark over the same days.
+type BenchmarkComparison struct {
+	From       time.Time                     `json:"from"`
+	To         time.Time                     `json:"to"`
+	Portfolio  []CumulativeReturn            `json:"portfolio"`
+	Benchmarks map[string][]CumulativeReturn `json:"benchmarks"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// BondValuation is a bond valued on a constant yield basis from its purchase price.
+// Prices are per bond in the bond's currency.
+type BondValuation struct {
+	AssetID         int             `json:"assetId"`
+	ValuedAt        time.Time       `json:"valuedAt"`
+	Quantity        float64         `json:"quantity"`
+	YieldToMaturity float64         `json:"yieldToMaturity"`
+	CleanPrice      float64         `json:"cleanPrice"`
+	AccruedInterest float64         `json:"accruedInterest"`
+	DirtyPrice      float64         `json:"dirtyPrice"`
+	CouponsReceived float64         `json:"couponsReceived"`
+	NextCouponDate  time.Time       `json:"nextCouponDate,omitempty"`
+	MaturityDate    time.Time       `json:"maturityDate"`
+	Matured         bool            `json:"matured"`
+	Coupons         []CouponPayment `json:"coupons"`
+}
+
+// CouponPayment is a scheduled coupon of a bond for the quantity currently held.
+type CouponPayment struct {
+	Date   time.Time `json:"date"`
+	Amount float64   `json:"amount"`
+	Paid   bool      `json:"paid"`
+}
+
+// Quote expresses the dirty price of the bond as a quote in the given currency.
+func (b BondValuation) Quote(currency string) Quote {
+	return Quote{Price: b.DirtyPrice, Currency: currency, Source: "accrual", Timestamp: b.ValuedAt}
+}
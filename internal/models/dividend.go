@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Dividend is a distribution per share of a stock, paid to whoever held it on the ex-date.
+type Dividend struct {
+	Symbol   string    `json:"symbol"`
+	ExDate   time.Time `json:"exDate"`
+	Amount   float64   `json:"amount"`
+	Currency string    `json:"currency"`
+}
+
+// DividendMonth is the dividend income of one calendar month in the base currency.
+type DividendMonth struct {
+	Month  time.Time `json:"month"`
+	Amount float64   `json:"amount"`
+}
+
+// AssetDividends is the dividend income of one asset in the base currency. YieldOnCost is
+// the income of the twelve months up to the end of the report as a percentage of what the
+// current holding cost.
+type AssetDividends struct {
+	AssetID      int     `json:"assetId"`
+	Name         string  `json:"name"`
+	Amount       float64 `json:"amount"`
+	TrailingYear float64 `json:"trailingYear"`
+	CostBasis    float64 `json:"costBasis"`
+	YieldOnCost  float64 `json:"yieldOnCost"`
+}
+
+// DividendReport is the dividend income received between From and To.
+type DividendReport struct {
+	From         time.Time        `json:"from"`
+	To           time.Time        `json:"to"`
+	BaseCurrency string           `json:"baseCurrency"`
+	Total        float64          `json:"total"`
+	ByMonth      []DividendMonth  `json:"byMonth"`
+	ByAsset      []AssetDividends `json:"byAsset"`
+}
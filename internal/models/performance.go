@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// ValuePoint is the base-currency value of a position at the end of a day together
+// with the net money put into it since the previous point.
+type ValuePoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+	Flow  float64   `json:"flow"`
+}
+
+// Performance measures the return on the money invested in a position, or a group of
+// positions, over a period. TWR ignores when money was added or taken out, MWR is the
+// XIRR of the actual cash flows and CAGR is the TWR annualized. MWR and CAGR are left
+// out when they cannot be determined from the data.
+type Performance struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	StartValue float64   `json:"startValue"`
+	EndValue   float64   `json:"endValue"`
+	NetFlows   float64   `json:"netFlows"`
+	Gain       float64   `json:"gain"`
+	TWR        float64   `json:"twr"`
+	MWR        *float64  `json:"mwr,omitempty"`
+	CAGR       *float64  `json:"cagr,omitempty"`
+}
+
+// PerformanceReport is the performance of every asset, every asset type and the whole portfolio.
+type PerformanceReport struct {
+	From         time.Time              `json:"from"`
+	To           time.Time              `json:"to"`
+	BaseCurrency string                 `json:"baseCurrency"`
+	Portfolio    Performance            `json:"portfolio"`
+	ByType       map[string]Performance `json:"byType"`
+	ByAsset      map[int]Performance    `json:"byAsset"`
+}
+
+// PeriodReturn is how a position did over one period, such as a calendar month. Change is
+// the gain in the base currency with money put in or taken out netted out, and Percent is
+// the time-weighted return. The period is measured from the last value before it, so
+// consecutive periods chain into the return over the whole range.
+type PeriodReturn struct {
+	Period     time.Time `json:"period"`
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	StartValue float64   `json:"startValue"`
+	EndValue   float64   `json:"endValue"`
+	NetFlows   float64   `json:"netFlows"`
+	Change     float64   `json:"change"`
+	Percent    float64   `json:"percent"`
+}
+
+// ReturnHistory is the return of every matching asset, and of all of them together, per period.
+type ReturnHistory struct {
+	Granularity  string                 `json:"granularity"`
+	From         time.Time              `json:"from"`
+	To           time.Time              `json:"to"`
+	BaseCurrency string                 `json:"baseCurrency"`
+	Total        []PeriodReturn         `json:"total"`
+	Assets       map[int][]PeriodReturn `json:"assets"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Portfolio groups some of a user's assets, such as a brokerage account or a pension.
+type Portfolio struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	LotMethod string    `json:"lotMethod"` // How sells of the portfolio's assets are matched to lots, empty for LOT_METHOD
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// Quote is a single price observation for a symbol together with the source it came from.
+type Quote struct {
+	Symbol    string    `json:"symbol"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
+	Source    string    `json:"source"`
+	Timestamp time.Time `json:"timestamp"`
+}
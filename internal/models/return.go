@@ -1,13 +1,45 @@
 package models
 
-
 import (
 	"time"
 )
 
 type AssetReturn struct {
-	ID       int       `json:"id"`
-	AssetID  int       `json:"asset_id"`
-	Date     time.Time `json:"date"`
-	Returns  float64   `json:"returns"`
+	ID         int       `json:"id"`
+	AssetID    int       `json:"asset_id"`
+	Date       time.Time `json:"date"`
+	Returns    float64   `json:"returns"`
+	Realized   float64   `json:"realized"`
+	Unrealized float64   `json:"unrealized"`
+}
+
+// PnL is the profit or loss of an asset split into the part locked in by
+// sells, fees and income and the part that only exists at current prices.
+type PnL struct {
+	AssetID    int     `json:"assetId"`
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+	Total      float64 `json:"total"`
+}
+
+func NewPnL(assetID int, realized, unrealized float64) PnL {
+	return PnL{AssetID: assetID, Realized: realized, Unrealized: unrealized, Total: realized + unrealized}
+}
+
+// Valuation is an asset marked to market in its own currency and in the
+// portfolio's base currency.
+type Valuation struct {
+	AssetID         int       `json:"assetId"`
+	Currency        string    `json:"currency"`
+	Quantity        float64   `json:"quantity"`
+	Price           float64   `json:"price"`
+	PriceSource     string    `json:"priceSource,omitempty"`
+	PricedAt        time.Time `json:"pricedAt,omitempty"`
+	MarketValue     float64   `json:"marketValue"`
+	CostBasis       float64   `json:"costBasis"`
+	PnL             PnL       `json:"pnl"`
+	BaseCurrency    string    `json:"baseCurrency"`
+	BaseMarketValue float64   `json:"baseMarketValue"`
+	BaseCostBasis   float64   `json:"baseCostBasis"`
+	BasePnL         PnL       `json:"basePnl"`
 }
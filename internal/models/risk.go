@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Drawdown is the largest fall of the portfolio from a previous high, as a fraction of that high.
+// Recovered is when the old high was reached again, if it was.
+type Drawdown struct {
+	Depth     float64    `json:"depth"`
+	Peak      time.Time  `json:"peak"`
+	Trough    time.Time  `json:"trough"`
+	Recovered *time.Time `json:"recovered,omitempty"`
+}
+
+// RiskReport describes how volatile the portfolio's returns were over a period. Ratios
+// that cannot be determined from the data, such as beta without benchmark prices, are left out.
+type RiskReport struct {
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	BaseCurrency     string    `json:"baseCurrency"`
+	Observations     int       `json:"observations"`
+	RiskFreeRate     float64   `json:"riskFreeRate"`
+	Return           float64   `json:"annualizedReturn"`
+	Volatility       float64   `json:"volatility"`
+	MaxDrawdown      Drawdown  `json:"maxDrawdown"`
+	Sharpe           *float64  `json:"sharpe,omitempty"`
+	Sortino          *float64  `json:"sortino,omitempty"`
+	Benchmark        string    `json:"benchmark,omitempty"`
+	Beta             *float64  `json:"beta,omitempty"`
+	BetaObservations int       `json:"betaObservations,omitempty"` // Fewer than Observations when the benchmark lacks closes for part of the period
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Snapshot is the state of an asset at the end of a day, or when AssetID is nil of a
+// portfolio, or of all of a user's portfolios when PortfolioID is nil too. Portfolio rows
+// only exist in the base currency.
+type Snapshot struct {
+	Date            time.Time `json:"date"`
+	AssetID         *int      `json:"assetId,omitempty"`
+	PortfolioID     *int      `json:"portfolioId,omitempty"`
+	OwnerID         *int      `json:"-"`
+	Currency        string    `json:"currency"`
+	Quantity        float64   `json:"quantity,omitempty"`
+	Price           float64   `json:"price,omitempty"`
+	MarketValue     float64   `json:"marketValue"`
+	CostBasis       float64   `json:"costBasis"`
+	PnL             PnL       `json:"pnl"`
+	BaseCurrency    string    `json:"baseCurrency"`
+	BaseMarketValue float64   `json:"baseMarketValue"`
+	BaseCostBasis   float64   `json:"baseCostBasis"`
+	BasePnL         PnL       `json:"basePnl"`
+}
@@ -0,0 +1,36 @@
+package models
+
+// AssetSummary is one asset's place in the portfolio, with amounts in the base currency
+// and Weight as the percentage of the portfolio's market value.
+type AssetSummary struct {
+	AssetID     int     `json:"assetId"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Currency    string  `json:"currency"`
+	Quantity    float64 `json:"quantity"`
+	Price       float64 `json:"price"`
+	PriceSource string  `json:"priceSource,omitempty"`
+	CostBasis   float64 `json:"costBasis"`
+	MarketValue float64 `json:"marketValue"`
+	PnL         PnL     `json:"pnl"`
+	Weight      float64 `json:"weight"`
+}
+
+// AllocationSummary adds up the assets of one type or currency.
+type AllocationSummary struct {
+	CostBasis   float64 `json:"costBasis"`
+	MarketValue float64 `json:"marketValue"`
+	PnL         PnL     `json:"pnl"`
+	Weight      float64 `json:"weight"`
+}
+
+// PortfolioSummary is the current allocation of the portfolio in its base currency.
+type PortfolioSummary struct {
+	BaseCurrency string                       `json:"baseCurrency"`
+	CostBasis    float64                      `json:"costBasis"`
+	MarketValue  float64                      `json:"marketValue"`
+	PnL          PnL                          `json:"pnl"`
+	Assets       []AssetSummary               `json:"assets"`
+	ByType       map[string]AllocationSummary `json:"byType"`
+	ByCurrency   map[string]AllocationSummary `json:"byCurrency"`
+}
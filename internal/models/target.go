@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Target is the percentage of the portfolio's market value an asset type or a single
+// asset should make up. Exactly one of AssetType and AssetID is set. An asset with a
+// target of its own is left out of its type's target.
+type Target struct {
+	ID        int       `json:"id"`
+	AssetType string    `json:"assetType,omitempty"`
+	AssetID   *int      `json:"assetId,omitempty"`
+	Percent   float64   `json:"percent"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// RebalanceTrade is what to buy (positive Amount) or sell (negative Amount), in the base
+// currency, to bring one target back to its share of the portfolio.
+type RebalanceTrade struct {
+	TargetID       int     `json:"targetId"`
+	AssetType      string  `json:"assetType,omitempty"`
+	AssetID        *int    `json:"assetId,omitempty"`
+	TargetPercent  float64 `json:"targetPercent"`
+	CurrentPercent float64 `json:"currentPercent"`
+	Drift          float64 `json:"drift"`
+	MarketValue    float64 `json:"marketValue"`
+	TargetValue    float64 `json:"targetValue"`
+	Amount         float64 `json:"amount"`
+}
+
+// RebalancePlan lists the trades that bring the portfolio, plus any new cash, back to its
+// targets. Unallocated is the part of the new cash the trades do not use, negative when
+// the trades need more than the cash and the sells raise.
+type RebalancePlan struct {
+	BaseCurrency string           `json:"baseCurrency"`
+	MarketValue  float64          `json:"marketValue"`
+	Cash         float64          `json:"cash"`
+	Threshold    float64          `json:"threshold"`
+	NoSell       bool             `json:"noSell"`
+	Trades       []RebalanceTrade `json:"trades"`
+	Unallocated  float64          `json:"unallocated"`
+}
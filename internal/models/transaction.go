@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+const (
+	TransactionBuy        = "buy"
+	TransactionSell       = "sell"
+	TransactionDeposit    = "deposit"
+	TransactionWithdrawal = "withdrawal"
+	TransactionDividend   = "dividend"
+	TransactionFee        = "fee"
+	TransactionCoupon     = "coupon"
+)
+
+// Transaction is a single ledger entry against an asset. Buys and sells use
+// Quantity and Price, cash movements (deposit, withdrawal, dividend, fee, coupon) use Amount.
+// A sell may name the buy it closes in LotID when specific-lot matching is used.
+type Transaction struct {
+	ID        int       `json:"id"`
+	AssetID   int       `json:"assetId"`
+	Type      string    `json:"type"`
+	Quantity  float64   `json:"quantity,omitempty"`
+	Price     float64   `json:"price,omitempty"`
+	Amount    float64   `json:"amount,omitempty"`
+	Fee       float64   `json:"fee,omitempty"`
+	LotID     *int      `json:"lotId,omitempty"`
+	Date      time.Time `json:"date"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// Holding is the current position in an asset derived from its transactions.
+type Holding struct {
+	AssetID     int     `json:"assetId"`
+	Quantity    float64 `json:"quantity"`
+	CostBasis   float64 `json:"costBasis"`
+	AverageCost float64 `json:"averageCost"`
+	Realized    float64 `json:"realized"`
+	Fees        float64 `json:"fees"`
+	Income      float64 `json:"income"`
+	Lots        []Lot   `json:"lots,omitempty"`
+}
+
+// Lot is the still open part of a single buy or deposit.
+type Lot struct {
+	TransactionID int       `json:"transactionId"`
+	Date          time.Time `json:"date"`
+	Quantity      float64   `json:"quantity"`
+	UnitCost      float64   `json:"unitCost"`
+}
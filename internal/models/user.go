@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// User owns assets and everything derived from them. The password is only ever stored as
+// its bcrypt hash.
+type User struct {
+	ID           int       `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// Credentials are what a user registers and logs in with.
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Session is a bearer token issued at login, sent back as "Authorization: Bearer <token>".
+type Session struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	User      *User     `json:"user"`
+}
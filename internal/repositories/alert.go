@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+const alertColumns = `r.id, r.kind, r.asset_id, r.portfolio_id, r.threshold, r.recipient, r.cooldown_minutes,
+	r.disabled, r.active, r.last_notified_at, r.created_at, r.owner_id`
+
+type AlertRepository struct {
+	DB *sql.DB
+}
+
+func NewAlertRepository(db *sql.DB) *AlertRepository {
+	return &AlertRepository{DB: db}
+}
+
+func (r *AlertRepository) AddRule(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (owner_id, kind, asset_id, portfolio_id, threshold, recipient, cooldown_minutes, disabled)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, owner(ctx), rule.Kind, rule.AssetID, rule.PortfolioID, rule.Threshold,
+		rule.Recipient, rule.CooldownMinutes, rule.Disabled).Scan(&rule.ID, &rule.CreatedAt)
+}
+
+// UpdateRule overwrites a rule and rearms it, returning sql.ErrNoRows if it does not exist.
+func (r *AlertRepository) UpdateRule(ctx context.Context, rule *models.AlertRule) error {
+	query := `
+		UPDATE alert_rules
+		SET kind = $1, asset_id = $2, portfolio_id = $3, threshold = $4, recipient = $5, cooldown_minutes = $6,
+		    disabled = $7, active = FALSE
+		WHERE id = $8 AND ($9::int IS NULL OR owner_id = $9)`
+
+	result, err := r.DB.ExecContext(ctx, query, rule.Kind, rule.AssetID, rule.PortfolioID, rule.Threshold,
+		rule.Recipient, rule.CooldownMinutes, rule.Disabled, rule.ID, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+func (r *AlertRepository) DeleteRule(ctx context.Context, id int) error {
+	query := `DELETE FROM alert_rules WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+func (r *AlertRepository) GetAllRules(ctx context.Context) ([]*models.AlertRule, error) {
+	query := `
+		SELECT ` + alertColumns + `
+		FROM alert_rules r
+		WHERE $1::int IS NULL OR r.owner_id = $1
+		ORDER BY r.id`
+
+	return r.query(ctx, query, owner(ctx))
+}
+
+// GetEnabledRules returns every enabled rule with the owner's email as its recipient, so
+// alerts only ever go to the owner, whatever address a rule was stored with.
+func (r *AlertRepository) GetEnabledRules(ctx context.Context) ([]*models.AlertRule, error) {
+	query := `
+		SELECT r.id, r.kind, r.asset_id, r.portfolio_id, r.threshold, u.email,
+			r.cooldown_minutes, r.disabled, r.active, r.last_notified_at, r.created_at, r.owner_id
+		FROM alert_rules r
+		JOIN users u ON u.id = r.owner_id
+		WHERE NOT r.disabled AND ($1::int IS NULL OR r.owner_id = $1)
+		ORDER BY r.owner_id, r.id`
+
+	return r.query(ctx, query, owner(ctx))
+}
+
+// MarkRule records whether a rule's condition holds after it was notified, and when it was
+// last notified if notified is set.
+func (r *AlertRepository) MarkRule(ctx context.Context, id int, active, notified bool) error {
+	query := `
+		UPDATE alert_rules
+		SET active = $2, last_notified_at = CASE WHEN $3::boolean THEN NOW() ELSE last_notified_at END
+		WHERE id = $1`
+
+	_, err := r.DB.ExecContext(ctx, query, id, active, notified)
+	return err
+}
+
+func (r *AlertRepository) query(ctx context.Context, query string, args ...any) ([]*models.AlertRule, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []*models.AlertRule
+	for rows.Next() {
+		var rule models.AlertRule
+		err := rows.Scan(&rule.ID, &rule.Kind, &rule.AssetID, &rule.PortfolioID, &rule.Threshold, &rule.Recipient,
+			&rule.CooldownMinutes, &rule.Disabled, &rule.Active, &rule.LastNotifiedAt, &rule.CreatedAt, &rule.OwnerID)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type APIKeyRepository struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{DB: db}
+}
+
+// AddKey stores a new key of the caller under the hash of its secret.
+func (r *APIKeyRepository) AddKey(ctx context.Context, key *models.APIKey, keyHash string) error {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, key_hash, scope)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, owner(ctx), key.Name, key.Prefix, keyHash, key.Scope).
+		Scan(&key.ID, &key.CreatedAt)
+}
+
+func (r *APIKeyRepository) GetAllKeys(ctx context.Context) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, name, prefix, scope, last_used_at, created_at
+		FROM api_keys
+		WHERE $1::int IS NULL OR user_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		var key models.APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.Scope, &key.LastUsedAt, &key.CreatedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &key)
+	}
+	return keys, rows.Err()
+}
+
+// DeleteKey revokes a key, returning sql.ErrNoRows if it does not exist.
+func (r *APIKeyRepository) DeleteKey(ctx context.Context, id int) error {
+	query := `DELETE FROM api_keys WHERE id = $1 AND ($2::int IS NULL OR user_id = $2)`
+
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// UseKey records that a key was used and returns its user and scope, or sql.ErrNoRows for
+// an unknown or revoked key.
+func (r *APIKeyRepository) UseKey(ctx context.Context, keyHash string) (int, string, error) {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE key_hash = $1
+		RETURNING user_id, scope`
+
+	var userID int
+	var scope string
+	err := r.DB.QueryRowContext(ctx, query, keyHash).Scan(&userID, &scope)
+	return userID, scope, err
+}
@@ -3,10 +3,18 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jagac/pfinance/internal/models"
 )
 
+// assetColumns lists the asset columns in the order scanAsset reads them, so
+// adding a column to the table does not silently break every Scan.
+const assetColumns = `id, name, type, ticker, price, amount, currency, interest_rate,
+	compounding_frequency, day_count, interest_start, face_value, coupon_rate, coupon_frequency, maturity_date,
+	purchase_price, portfolio_id, owner_id, created_at,
+	COALESCE((SELECT p.lot_method FROM portfolios p WHERE p.id = assets.portfolio_id), '')`
+
 type AssetRepository struct {
 	DB *sql.DB
 }
@@ -17,34 +25,76 @@ func NewAssetRepository(db *sql.DB) *AssetRepository {
 
 func (r *AssetRepository) AddAsset(ctx context.Context, asset *models.Asset) error {
 	query := `
-		INSERT INTO assets (type, name, ticker, price, amount, currency, interest_rate, compounding_frequency, interest_start)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+		INSERT INTO assets (type, name, ticker, price, amount, currency, interest_rate, compounding_frequency, interest_start,
+		                    face_value, coupon_rate, coupon_frequency, maturity_date, purchase_price, day_count, owner_id,
+		                    portfolio_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, COALESCE(NULLIF($15, ''), 'act/365'), $16, $17)`
 
 	_, err := r.DB.ExecContext(ctx, query,
 		asset.Type, asset.Name, asset.Ticker, asset.Price, asset.Amount,
-		asset.Currency, asset.InterestRate, asset.CompoundingFrequency, asset.InterestStart)
+		asset.Currency, asset.InterestRate, asset.CompoundingFrequency, asset.InterestStart,
+		asset.FaceValue, asset.CouponRate, asset.CouponFrequency, nullTime(asset.MaturityDate), asset.PurchasePrice,
+		asset.DayCount, owner(ctx), asset.PortfolioID)
 
 	return err
 }
 
-func (r *AssetRepository) GetAssetByID(ctx context.Context, id int) (*models.Asset, error) {
-	query := `SELECT * FROM assets WHERE id = $1`
-	row := r.DB.QueryRowContext(ctx, query, id)
+// UpdateAsset overwrites every editable column of the asset, returning sql.ErrNoRows if it does not exist.
+func (r *AssetRepository) UpdateAsset(ctx context.Context, asset *models.Asset) error {
+	query := `
+		UPDATE assets
+		SET type = $1, name = $2, ticker = $3, price = $4, amount = $5, currency = $6,
+		    interest_rate = $7, compounding_frequency = $8, interest_start = $9,
+		    face_value = $10, coupon_rate = $11, coupon_frequency = $12, maturity_date = $13, purchase_price = $14,
+		    day_count = COALESCE(NULLIF($15, ''), 'act/365'), portfolio_id = $18
+		WHERE id = $16 AND ($17::int IS NULL OR owner_id = $17)`
+
+	result, err := r.DB.ExecContext(ctx, query,
+		asset.Type, asset.Name, asset.Ticker, asset.Price, asset.Amount,
+		asset.Currency, asset.InterestRate, asset.CompoundingFrequency, asset.InterestStart,
+		asset.FaceValue, asset.CouponRate, asset.CouponFrequency, nullTime(asset.MaturityDate), asset.PurchasePrice,
+		asset.DayCount, asset.ID, owner(ctx), asset.PortfolioID)
+	if err != nil {
+		return err
+	}
 
-	var asset models.Asset
-	err := row.Scan(&asset.ID, &asset.Name, &asset.Type, &asset.Ticker, &asset.Price, &asset.Amount,
-		&asset.Currency, &asset.InterestRate, &asset.CompoundingFrequency, &asset.InterestStart)
+	return expectRows(result)
+}
 
+// DeleteAsset removes the asset together with its returns and transactions, returning sql.ErrNoRows if it does not exist.
+func (r *AssetRepository) DeleteAsset(ctx context.Context, id int) error {
+	query := `DELETE FROM assets WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return &asset, nil
+	return expectRows(result)
+}
+
+// expectRows turns a statement that touched no rows into sql.ErrNoRows.
+func expectRows(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *AssetRepository) GetAssetByID(ctx context.Context, id int) (*models.Asset, error) {
+	query := `SELECT ` + assetColumns + ` FROM assets WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+	row := r.DB.QueryRowContext(ctx, query, id, owner(ctx))
+
+	return scanAsset(row)
 }
 
 func (r *AssetRepository) GetAllAssets(ctx context.Context) ([]*models.Asset, error) {
-	query := `SELECT * FROM assets`
-	rows, err := r.DB.QueryContext(ctx, query)
+	query := `SELECT ` + assetColumns + ` FROM assets
+		WHERE ($1::int IS NULL OR owner_id = $1) AND ($2::int IS NULL OR portfolio_id = $2)`
+	rows, err := r.DB.QueryContext(ctx, query, owner(ctx), portfolio(ctx))
 
 	if err != nil {
 		return nil, err
@@ -53,13 +103,11 @@ func (r *AssetRepository) GetAllAssets(ctx context.Context) ([]*models.Asset, er
 
 	var assets []*models.Asset
 	for rows.Next() {
-		var asset models.Asset
-		err := rows.Scan(&asset.ID, &asset.Name, &asset.Type, &asset.Ticker, &asset.Price, &asset.Amount,
-			&asset.Currency, &asset.InterestRate, &asset.CompoundingFrequency, &asset.InterestStart, &asset.CreatedAt)
+		asset, err := scanAsset(rows)
 		if err != nil {
 			return nil, err
 		}
-		assets = append(assets, &asset)
+		assets = append(assets, asset)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -70,8 +118,9 @@ func (r *AssetRepository) GetAllAssets(ctx context.Context) ([]*models.Asset, er
 }
 
 func (r *AssetRepository) GetAssetsByType(ctx context.Context, assetType string) ([]*models.Asset, error) {
-	query := `SELECT * FROM assets WHERE type = $1`
-	rows, err := r.DB.QueryContext(ctx, query, assetType)
+	query := `SELECT ` + assetColumns + ` FROM assets
+		WHERE type = $1 AND ($2::int IS NULL OR owner_id = $2) AND ($3::int IS NULL OR portfolio_id = $3)`
+	rows, err := r.DB.QueryContext(ctx, query, assetType, owner(ctx), portfolio(ctx))
 
 	if err != nil {
 		return nil, err
@@ -80,13 +129,11 @@ func (r *AssetRepository) GetAssetsByType(ctx context.Context, assetType string)
 
 	var assets []*models.Asset
 	for rows.Next() {
-		var asset models.Asset
-		err := rows.Scan(&asset.ID, &asset.Name, &asset.Type, &asset.Ticker, &asset.Price, &asset.Amount,
-			&asset.Currency, &asset.InterestRate, &asset.CompoundingFrequency, &asset.InterestStart, &asset.CreatedAt)
+		asset, err := scanAsset(rows)
 		if err != nil {
 			return nil, err
 		}
-		assets = append(assets, &asset)
+		assets = append(assets, asset)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -95,3 +142,23 @@ func (r *AssetRepository) GetAssetsByType(ctx context.Context, assetType string)
 
 	return assets, nil
 }
+
+// scanAsset reads a row selected with assetColumns.
+func scanAsset(row interface{ Scan(dest ...any) error }) (*models.Asset, error) {
+	var asset models.Asset
+	var maturityDate sql.NullTime
+	err := row.Scan(&asset.ID, &asset.Name, &asset.Type, &asset.Ticker, &asset.Price, &asset.Amount,
+		&asset.Currency, &asset.InterestRate, &asset.CompoundingFrequency, &asset.DayCount, &asset.InterestStart,
+		&asset.FaceValue, &asset.CouponRate, &asset.CouponFrequency, &maturityDate, &asset.PurchasePrice, &asset.PortfolioID,
+		&asset.OwnerID, &asset.CreatedAt, &asset.LotMethod)
+	if err != nil {
+		return nil, err
+	}
+	asset.MaturityDate = maturityDate.Time
+	return &asset, nil
+}
+
+// nullTime stores a zero time as NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
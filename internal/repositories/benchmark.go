@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type BenchmarkRepository struct {
+	DB *sql.DB
+}
+
+func NewBenchmarkRepository(db *sql.DB) *BenchmarkRepository {
+	return &BenchmarkRepository{DB: db}
+}
+
+func (r *BenchmarkRepository) AddBenchmark(ctx context.Context, benchmark *models.Benchmark) error {
+	query := `
+		INSERT INTO benchmarks (ticker, name, owner_id)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, benchmark.Ticker, benchmark.Name, owner(ctx)).
+		Scan(&benchmark.ID, &benchmark.CreatedAt)
+}
+
+func (r *BenchmarkRepository) GetAllBenchmarks(ctx context.Context) ([]*models.Benchmark, error) {
+	query := `
+		SELECT id, ticker, COALESCE(name, ''), created_at
+		FROM benchmarks
+		WHERE $1::int IS NULL OR owner_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var benchmarks []*models.Benchmark
+	for rows.Next() {
+		var benchmark models.Benchmark
+		if err := rows.Scan(&benchmark.ID, &benchmark.Ticker, &benchmark.Name, &benchmark.CreatedAt); err != nil {
+			return nil, err
+		}
+		benchmarks = append(benchmarks, &benchmark)
+	}
+	return benchmarks, rows.Err()
+}
+
+func (r *BenchmarkRepository) DeleteBenchmark(ctx context.Context, id int) error {
+	query := `DELETE FROM benchmarks WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
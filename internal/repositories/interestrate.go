@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type InterestRateRepository struct {
+	DB *sql.DB
+}
+
+func NewInterestRateRepository(db *sql.DB) *InterestRateRepository {
+	return &InterestRateRepository{DB: db}
+}
+
+// AddRate stores a rate change, replacing one already effective from the same day.
+func (r *InterestRateRepository) AddRate(ctx context.Context, rate *models.InterestRate) error {
+	query := `
+		INSERT INTO interest_rates (asset_id, rate, effective_from)
+		VALUES ($1, $2, $3::date)
+		ON CONFLICT (asset_id, effective_from) DO UPDATE SET rate = EXCLUDED.rate
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, rate.AssetID, rate.Rate, rate.EffectiveFrom).
+		Scan(&rate.ID, &rate.CreatedAt)
+}
+
+// GetRatesByAsset returns the rate changes of an asset, oldest first.
+func (r *InterestRateRepository) GetRatesByAsset(ctx context.Context, assetID int) ([]models.InterestRate, error) {
+	query := `
+		SELECT ir.id, ir.asset_id, ir.rate, ir.effective_from, ir.created_at
+		FROM interest_rates ir
+		JOIN assets a ON a.id = ir.asset_id
+		WHERE ir.asset_id = $1 AND ($2::int IS NULL OR a.owner_id = $2)
+		ORDER BY ir.effective_from`
+
+	rows, err := r.DB.QueryContext(ctx, query, assetID, owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []models.InterestRate
+	for rows.Next() {
+		var rate models.InterestRate
+		if err := rows.Scan(&rate.ID, &rate.AssetID, &rate.Rate, &rate.EffectiveFrom, &rate.CreatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+// DeleteRate removes a rate change of an asset, returning sql.ErrNoRows if it does not exist.
+func (r *InterestRateRepository) DeleteRate(ctx context.Context, assetID, id int) error {
+	query := `
+		DELETE FROM interest_rates ir
+		USING assets a
+		WHERE a.id = ir.asset_id AND ir.id = $1 AND ir.asset_id = $2 AND ($3::int IS NULL OR a.owner_id = $3)`
+
+	result, err := r.DB.ExecContext(ctx, query, id, assetID, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
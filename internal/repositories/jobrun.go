@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// JobRunRepository is the ledger of the days a scheduled job has completed.
+type JobRunRepository struct {
+	DB *sql.DB
+}
+
+func NewJobRunRepository(db *sql.DB) *JobRunRepository {
+	return &JobRunRepository{DB: db}
+}
+
+// MarkCompleted records that a job finished its work for a day.
+func (r *JobRunRepository) MarkCompleted(ctx context.Context, job string, date time.Time) error {
+	query := `
+		INSERT INTO job_runs (job, date)
+		VALUES ($1, $2::date)
+		ON CONFLICT (job, date) DO UPDATE SET completed_at = NOW()`
+
+	_, err := r.DB.ExecContext(ctx, query, job, date)
+	return err
+}
+
+// LastCompleted returns the latest day a job completed, or sql.ErrNoRows if it never did.
+func (r *JobRunRepository) LastCompleted(ctx context.Context, job string) (time.Time, error) {
+	var date sql.NullTime
+	err := r.DB.QueryRowContext(ctx, `SELECT MAX(date) FROM job_runs WHERE job = $1`, job).Scan(&date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !date.Valid {
+		return time.Time{}, sql.ErrNoRows
+	}
+	return date.Time, nil
+}
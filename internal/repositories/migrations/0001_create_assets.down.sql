@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS asset_returns;
+DROP TABLE IF EXISTS assets;
@@ -1,4 +1,4 @@
-CREATE TABLE assets (
+CREATE TABLE IF NOT EXISTS assets (
     id SERIAL PRIMARY KEY,
     name VARCHAR(255) NOT NULL,
     type VARCHAR(50) CHECK (type IN ('Stock', 'Gold', 'Bond', 'Savings', 'Crypto')) NOT NULL,
@@ -12,7 +12,7 @@ CREATE TABLE assets (
     created_at TIMESTAMP DEFAULT NOW()
 );
 
-CREATE TABLE asset_returns (
+CREATE TABLE IF NOT EXISTS asset_returns (
     id SERIAL PRIMARY KEY,
     asset_id INT REFERENCES assets(id) ON DELETE CASCADE,
     date DATE NOT NULL DEFAULT CURRENT_DATE,
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS transactions (
+    id SERIAL PRIMARY KEY,
+    asset_id INT NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    type VARCHAR(20) CHECK (type IN ('buy', 'sell', 'deposit', 'withdrawal', 'dividend', 'fee')) NOT NULL,
+    quantity NUMERIC(18,8) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    price NUMERIC(18,4) NOT NULL DEFAULT 0 CHECK (price >= 0),
+    amount NUMERIC(18,2) NOT NULL DEFAULT 0 CHECK (amount >= 0),
+    fee NUMERIC(18,2) NOT NULL DEFAULT 0 CHECK (fee >= 0),
+    lot_id INT REFERENCES transactions(id) ON DELETE SET NULL, -- Buy closed by a specific-lot sell
+    date DATE NOT NULL DEFAULT CURRENT_DATE,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS transactions_asset_id_date_idx ON transactions (asset_id, date);
@@ -0,0 +1,3 @@
+ALTER TABLE asset_returns
+    DROP COLUMN IF EXISTS realized,
+    DROP COLUMN IF EXISTS unrealized;
@@ -0,0 +1,3 @@
+ALTER TABLE asset_returns
+    ADD COLUMN IF NOT EXISTS realized NUMERIC(18,4) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS unrealized NUMERIC(18,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,11 @@
+DELETE FROM transactions WHERE type = 'coupon';
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('buy', 'sell', 'deposit', 'withdrawal', 'dividend', 'fee'));
+
+ALTER TABLE assets
+    DROP COLUMN IF EXISTS face_value,
+    DROP COLUMN IF EXISTS coupon_rate,
+    DROP COLUMN IF EXISTS coupon_frequency,
+    DROP COLUMN IF EXISTS maturity_date,
+    DROP COLUMN IF EXISTS purchase_price;
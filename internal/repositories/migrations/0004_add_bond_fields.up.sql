@@ -0,0 +1,10 @@
+ALTER TABLE assets
+    ADD COLUMN IF NOT EXISTS face_value NUMERIC(18,2) NOT NULL DEFAULT 0, -- Only for bonds, per bond
+    ADD COLUMN IF NOT EXISTS coupon_rate NUMERIC(5,2) NOT NULL DEFAULT 0, -- Annual coupon in percent of face value
+    ADD COLUMN IF NOT EXISTS coupon_frequency INT NOT NULL DEFAULT 0 CHECK (coupon_frequency IN (0, 1, 2, 4, 12)),
+    ADD COLUMN IF NOT EXISTS maturity_date DATE,
+    ADD COLUMN IF NOT EXISTS purchase_price NUMERIC(18,4) NOT NULL DEFAULT 0; -- Clean price paid per bond
+
+ALTER TABLE transactions DROP CONSTRAINT IF EXISTS transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('buy', 'sell', 'deposit', 'withdrawal', 'dividend', 'fee', 'coupon'));
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS prices (
+    id SERIAL PRIMARY KEY,
+    asset_type VARCHAR(50) NOT NULL,
+    symbol VARCHAR(50) NOT NULL, -- Ticker, coin id or XAU for gold
+    source VARCHAR(50) NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    price NUMERIC(18,6) NOT NULL,
+    quoted_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS prices_symbol_quoted_at_idx ON prices (asset_type, symbol, quoted_at DESC);
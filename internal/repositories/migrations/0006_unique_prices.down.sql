@@ -0,0 +1 @@
+DROP INDEX IF EXISTS prices_unique_quote_idx;
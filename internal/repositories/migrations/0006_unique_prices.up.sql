@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS prices_unique_quote_idx ON prices (asset_type, symbol, source, quoted_at);
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS benchmarks (
+    id SERIAL PRIMARY KEY,
+    ticker VARCHAR(20) NOT NULL UNIQUE,
+    name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT NOW()
+);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS targets (
+    id SERIAL PRIMARY KEY,
+    asset_type VARCHAR(50) CHECK (asset_type IN ('Stock', 'Gold', 'Bond', 'Savings', 'Crypto')),
+    asset_id INT REFERENCES assets(id) ON DELETE CASCADE,
+    percent NUMERIC(5,2) NOT NULL CHECK (percent > 0 AND percent <= 100),
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK ((asset_type IS NULL) <> (asset_id IS NULL)) -- A target is either for a type or for an asset
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS targets_asset_type_idx ON targets (asset_type) WHERE asset_type IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS targets_asset_id_idx ON targets (asset_id) WHERE asset_id IS NOT NULL;
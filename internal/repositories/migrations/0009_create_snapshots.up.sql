@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS snapshots (
+    id SERIAL PRIMARY KEY,
+    date DATE NOT NULL DEFAULT CURRENT_DATE,
+    asset_id INT REFERENCES assets(id) ON DELETE CASCADE, -- NULL for the portfolio total
+    currency VARCHAR(10) NOT NULL,
+    quantity NUMERIC(18,6) NOT NULL DEFAULT 0,
+    price NUMERIC(18,6) NOT NULL DEFAULT 0,
+    market_value NUMERIC(18,4) NOT NULL,
+    cost_basis NUMERIC(18,4) NOT NULL,
+    realized NUMERIC(18,4) NOT NULL,
+    unrealized NUMERIC(18,4) NOT NULL,
+    base_currency VARCHAR(10) NOT NULL,
+    base_market_value NUMERIC(18,4) NOT NULL,
+    base_cost_basis NUMERIC(18,4) NOT NULL,
+    base_realized NUMERIC(18,4) NOT NULL,
+    base_unrealized NUMERIC(18,4) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS snapshots_date_asset_idx ON snapshots (date, COALESCE(asset_id, 0));
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS job_runs;
+ALTER TABLE asset_returns DROP CONSTRAINT IF EXISTS asset_returns_asset_date_key;
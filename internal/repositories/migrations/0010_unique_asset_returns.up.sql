@@ -0,0 +1,14 @@
+-- Keep only the latest row recorded for an asset on a day before enforcing one per day.
+DELETE FROM asset_returns a
+USING asset_returns b
+WHERE a.asset_id = b.asset_id AND a.date = b.date AND a.id < b.id;
+
+ALTER TABLE asset_returns ADD CONSTRAINT asset_returns_asset_date_key UNIQUE (asset_id, date);
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id SERIAL PRIMARY KEY,
+    job VARCHAR(50) NOT NULL,
+    date DATE NOT NULL,
+    completed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (job, date)
+);
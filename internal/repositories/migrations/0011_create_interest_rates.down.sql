@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS interest_rates;
+ALTER TABLE assets DROP COLUMN IF EXISTS day_count;
@@ -0,0 +1,12 @@
+ALTER TABLE assets
+    ADD COLUMN IF NOT EXISTS day_count VARCHAR(10) NOT NULL DEFAULT 'act/365'
+        CHECK (day_count IN ('act/365', 'act/360', '30/360')); -- Only for savings
+
+CREATE TABLE IF NOT EXISTS interest_rates (
+    id SERIAL PRIMARY KEY,
+    asset_id INT NOT NULL REFERENCES assets(id) ON DELETE CASCADE,
+    rate NUMERIC(5,2) NOT NULL CHECK (rate >= 0), -- Annual rate in percent
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (asset_id, effective_from)
+);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS targets_owner_asset_type_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS targets_asset_type_idx ON targets (asset_type) WHERE asset_type IS NOT NULL;
+DROP INDEX IF EXISTS snapshots_date_owner_asset_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS snapshots_date_asset_idx ON snapshots (date, COALESCE(asset_id, 0));
+
+ALTER TABLE targets DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE snapshots DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE asset_returns DROP COLUMN IF EXISTS owner_id;
+ALTER TABLE assets DROP COLUMN IF EXISTS owner_id;
+
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS users;
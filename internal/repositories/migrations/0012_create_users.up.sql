@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS users (
+    id SERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+    token_hash CHAR(64) PRIMARY KEY, -- SHA-256 of the bearer token, which is never stored
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Rows created before accounts existed have no owner until `pfinance adopt` hands them to a user.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE asset_returns ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE targets ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS assets_owner_idx ON assets (owner_id);
+CREATE INDEX IF NOT EXISTS asset_returns_owner_date_idx ON asset_returns (owner_id, date);
+
+-- Every user has their own portfolio total and their own type targets.
+DROP INDEX IF EXISTS snapshots_date_asset_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS snapshots_date_owner_asset_idx
+    ON snapshots (date, COALESCE(owner_id, 0), COALESCE(asset_id, 0));
+DROP INDEX IF EXISTS targets_asset_type_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS targets_owner_asset_type_idx
+    ON targets (COALESCE(owner_id, 0), asset_type) WHERE asset_type IS NOT NULL;
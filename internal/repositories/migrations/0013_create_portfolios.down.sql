@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS snapshots_date_owner_portfolio_asset_idx;
+DELETE FROM snapshots WHERE portfolio_id IS NOT NULL;
+ALTER TABLE snapshots DROP COLUMN IF EXISTS portfolio_id;
+CREATE UNIQUE INDEX IF NOT EXISTS snapshots_date_owner_asset_idx
+    ON snapshots (date, COALESCE(owner_id, 0), COALESCE(asset_id, 0));
+
+ALTER TABLE assets DROP COLUMN IF EXISTS portfolio_id;
+DROP TABLE IF EXISTS portfolios;
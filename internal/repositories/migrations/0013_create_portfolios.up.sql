@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS portfolios (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (owner_id, name)
+);
+
+-- Assets outside any portfolio only count towards their owner's totals.
+ALTER TABLE assets ADD COLUMN IF NOT EXISTS portfolio_id INT REFERENCES portfolios(id);
+CREATE INDEX IF NOT EXISTS assets_portfolio_idx ON assets (portfolio_id);
+
+-- Existing assets start out in one portfolio per owner.
+INSERT INTO portfolios (owner_id, name)
+SELECT DISTINCT owner_id, 'Main' FROM assets WHERE owner_id IS NOT NULL
+ON CONFLICT (owner_id, name) DO NOTHING;
+
+UPDATE assets a SET portfolio_id = p.id
+FROM portfolios p
+WHERE p.owner_id = a.owner_id AND p.name = 'Main' AND a.portfolio_id IS NULL;
+
+-- The total of a portfolio has its portfolio_id set; the total across an owner's portfolios has none.
+ALTER TABLE snapshots ADD COLUMN IF NOT EXISTS portfolio_id INT REFERENCES portfolios(id) ON DELETE CASCADE;
+DROP INDEX IF EXISTS snapshots_date_owner_asset_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS snapshots_date_owner_portfolio_asset_idx
+    ON snapshots (date, COALESCE(owner_id, 0), COALESCE(portfolio_id, 0), COALESCE(asset_id, 0));
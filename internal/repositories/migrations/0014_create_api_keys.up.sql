@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(16) NOT NULL, -- The start of the key, shown so it can be told apart
+    key_hash CHAR(64) NOT NULL UNIQUE, -- SHA-256 of the key, which is never stored
+    scope VARCHAR(10) NOT NULL CHECK (scope IN ('read', 'write')),
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS api_keys_user_idx ON api_keys (user_id);
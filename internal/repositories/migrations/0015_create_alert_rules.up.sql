@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id SERIAL PRIMARY KEY,
+    owner_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('price_above', 'price_below', 'daily_change', 'drawdown', 'drift')),
+    asset_id INT REFERENCES assets(id) ON DELETE CASCADE, -- Required by price rules, NULL for the whole portfolio
+    portfolio_id INT REFERENCES portfolios(id) ON DELETE CASCADE, -- NULL for all of the owner's portfolios
+    threshold NUMERIC(18,6) NOT NULL CHECK (threshold > 0),
+    recipient VARCHAR(255) NOT NULL DEFAULT '', -- Empty sends to the owner's email
+    cooldown_minutes INT NOT NULL DEFAULT 1440 CHECK (cooldown_minutes >= 0),
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    active BOOLEAN NOT NULL DEFAULT FALSE, -- Whether the alert was sent and its condition still holds
+    last_notified_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (kind NOT IN ('price_above', 'price_below') OR asset_id IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS alert_rules_owner_idx ON alert_rules (owner_id);
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS benchmarks_owner_ticker_idx;
+-- Users may have picked the same ticker, which has to be unique again
+DELETE FROM benchmarks a USING benchmarks b WHERE a.ticker = b.ticker AND a.id > b.id;
+ALTER TABLE benchmarks ADD CONSTRAINT benchmarks_ticker_key UNIQUE (ticker);
+
+ALTER TABLE benchmarks DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,6 @@
+-- Every user has their own benchmarks. Benchmarks added before accounts existed have no
+-- owner until `pfinance adopt` hands them to a user.
+ALTER TABLE benchmarks ADD COLUMN IF NOT EXISTS owner_id INT REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE benchmarks DROP CONSTRAINT IF EXISTS benchmarks_ticker_key;
+CREATE UNIQUE INDEX IF NOT EXISTS benchmarks_owner_ticker_idx ON benchmarks (COALESCE(owner_id, 0), ticker);
@@ -0,0 +1,4 @@
+-- How sells are matched against the lots of the portfolio's assets. Existing portfolios
+-- keep following the server's LOT_METHOD until one is chosen.
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS lot_method VARCHAR(10)
+    CHECK (lot_method IN ('fifo', 'lifo', 'average', 'specific'));
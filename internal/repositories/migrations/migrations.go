@@ -0,0 +1,7 @@
+// Package migrations embeds the versioned SQL migrations of the pfinance schema.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
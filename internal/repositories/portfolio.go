@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type portfolioKey struct{}
+
+// WithPortfolio narrows the asset, return, transaction and snapshot queries made with the
+// returned context to one portfolio. Without it they aggregate across all portfolios.
+func WithPortfolio(ctx context.Context, portfolioID int) context.Context {
+	return context.WithValue(ctx, portfolioKey{}, portfolioID)
+}
+
+// portfolio is the query argument for the portfolio a context is narrowed to, NULL for all.
+func portfolio(ctx context.Context) *int {
+	if portfolioID, ok := ctx.Value(portfolioKey{}).(int); ok {
+		return &portfolioID
+	}
+	return nil
+}
+
+type PortfolioRepository struct {
+	DB *sql.DB
+}
+
+func NewPortfolioRepository(db *sql.DB) *PortfolioRepository {
+	return &PortfolioRepository{DB: db}
+}
+
+func (r *PortfolioRepository) AddPortfolio(ctx context.Context, p *models.Portfolio) error {
+	query := `
+		INSERT INTO portfolios (owner_id, name, lot_method)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, owner(ctx), p.Name, p.LotMethod).Scan(&p.ID, &p.CreatedAt)
+}
+
+func (r *PortfolioRepository) UpdatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	query := `UPDATE portfolios SET name = $1, lot_method = $4 WHERE id = $2 AND ($3::int IS NULL OR owner_id = $3)`
+
+	result, err := r.DB.ExecContext(ctx, query, p.Name, p.ID, owner(ctx), p.LotMethod)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+// DeletePortfolio removes an empty portfolio together with its snapshots, returning
+// sql.ErrNoRows if it does not exist.
+func (r *PortfolioRepository) DeletePortfolio(ctx context.Context, id int) error {
+	query := `DELETE FROM portfolios WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+func (r *PortfolioRepository) GetPortfolioByID(ctx context.Context, id int) (*models.Portfolio, error) {
+	query := `SELECT id, name, COALESCE(lot_method, ''), created_at FROM portfolios WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+
+	var p models.Portfolio
+	if err := r.DB.QueryRowContext(ctx, query, id, owner(ctx)).Scan(&p.ID, &p.Name, &p.LotMethod, &p.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PortfolioRepository) GetAllPortfolios(ctx context.Context) ([]*models.Portfolio, error) {
+	query := `
+		SELECT id, name, COALESCE(lot_method, ''), created_at
+		FROM portfolios
+		WHERE $1::int IS NULL OR owner_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var portfolios []*models.Portfolio
+	for rows.Next() {
+		var p models.Portfolio
+		if err := rows.Scan(&p.ID, &p.Name, &p.LotMethod, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		portfolios = append(portfolios, &p)
+	}
+	return portfolios, rows.Err()
+}
+
+// CountAssets returns how many assets a portfolio holds.
+func (r *PortfolioRepository) CountAssets(ctx context.Context, id int) (int, error) {
+	var count int
+	err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM assets WHERE portfolio_id = $1`, id).Scan(&count)
+	return count, err
+}
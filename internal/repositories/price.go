@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type PriceRepository struct {
+	DB *sql.DB
+}
+
+func NewPriceRepository(db *sql.DB) *PriceRepository {
+	return &PriceRepository{DB: db}
+}
+
+// InsertQuote stores a quote, ignoring one already stored for the same source and time.
+func (r *PriceRepository) InsertQuote(ctx context.Context, assetType string, quote models.Quote) error {
+	query := `
+		INSERT INTO prices (asset_type, symbol, source, currency, price, quoted_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (asset_type, symbol, source, quoted_at) DO NOTHING`
+
+	_, err := r.DB.ExecContext(ctx, query,
+		assetType, quote.Symbol, quote.Source, quote.Currency, quote.Price, quote.Timestamp)
+	return err
+}
+
+// GetLatestQuote returns the most recent persisted quote of a symbol, or sql.ErrNoRows if it was never quoted.
+func (r *PriceRepository) GetLatestQuote(ctx context.Context, assetType, symbol string) (models.Quote, error) {
+	query := `
+		SELECT symbol, price, currency, source, quoted_at
+		FROM prices
+		WHERE asset_type = $1 AND symbol = $2
+		ORDER BY quoted_at DESC
+		LIMIT 1`
+
+	var quote models.Quote
+	err := r.DB.QueryRowContext(ctx, query, assetType, symbol).
+		Scan(&quote.Symbol, &quote.Price, &quote.Currency, &quote.Source, &quote.Timestamp)
+	return quote, err
+}
+
+// GetDailyCloses returns the last persisted quote of a symbol on each day between from and to inclusive, oldest first.
+func (r *PriceRepository) GetDailyCloses(ctx context.Context, assetType, symbol string, from, to time.Time) ([]models.Quote, error) {
+	query := `
+		SELECT DISTINCT ON (quoted_at::date) symbol, price, currency, source, quoted_at
+		FROM prices
+		WHERE asset_type = $1 AND symbol = $2 AND quoted_at >= $3::date AND quoted_at < $4::date + 1
+		ORDER BY quoted_at::date, quoted_at DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, assetType, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var quotes []models.Quote
+	for rows.Next() {
+		var quote models.Quote
+		if err := rows.Scan(&quote.Symbol, &quote.Price, &quote.Currency, &quote.Source, &quote.Timestamp); err != nil {
+			return nil, err
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, rows.Err()
+}
@@ -5,6 +5,8 @@ import (
 	"database/sql"
 	"log"
 	"time"
+
+	"github.com/jagac/pfinance/internal/models"
 )
 
 type AssetReturnHistoryRepository struct {
@@ -15,75 +17,77 @@ func NewAssetReturnHistoryRepository(db *sql.DB) *AssetReturnHistoryRepository {
 	return &AssetReturnHistoryRepository{DB: db}
 }
 
-func (r *AssetReturnHistoryRepository) InsertAssetReturn(ctx context.Context, assetID int, returns float32, date *time.Time) error {
-	query := `INSERT INTO asset_returns (asset_id, date, returns)
-              VALUES ($1, COALESCE($2, CURRENT_DATE), $3)`
+// UpsertAssetReturn records the P&L of an asset for a day, today when date is nil,
+// replacing what was already recorded for that day.
+func (r *AssetReturnHistoryRepository) UpsertAssetReturn(ctx context.Context, pnl models.PnL, date *time.Time) error {
+	query := `INSERT INTO asset_returns (asset_id, date, returns, realized, unrealized, owner_id)
+              VALUES ($1, COALESCE($2::date, CURRENT_DATE), $3, $4, $5, (SELECT owner_id FROM assets WHERE id = $1))
+              ON CONFLICT (asset_id, date) DO UPDATE SET
+                  returns = EXCLUDED.returns,
+                  realized = EXCLUDED.realized,
+                  unrealized = EXCLUDED.unrealized`
 
-	_, err := r.DB.ExecContext(ctx, query, assetID, date, returns)
+	_, err := r.DB.ExecContext(ctx, query, pnl.AssetID, date, pnl.Total, pnl.Realized, pnl.Unrealized)
 	if err != nil {
-		log.Println("Error inserting asset return:", err)
+		log.Println("Error recording asset return:", err)
 		return err
 	}
 
-	log.Println("Inserted asset return successfully")
+	log.Println("Recorded asset return successfully")
 	return nil
 }
 
-type MonthlyReturn struct {
-	AssetID     int
-	AssetName   string
-	Year        int
-	Month       int
-	FirstReturn float64
-	LastReturn  float64
+// InsertAssetReturnIfMissing records the P&L of an asset for a past day unless that day
+// is already recorded, reporting whether a row was written.
+func (r *AssetReturnHistoryRepository) InsertAssetReturnIfMissing(ctx context.Context, pnl models.PnL, date time.Time) (bool, error) {
+	query := `
+		INSERT INTO asset_returns (asset_id, date, returns, realized, unrealized, owner_id)
+		VALUES ($1, $2::date, $3, $4, $5, (SELECT owner_id FROM assets WHERE id = $1))
+		ON CONFLICT (asset_id, date) DO NOTHING`
+
+	result, err := r.DB.ExecContext(ctx, query, pnl.AssetID, date, pnl.Total, pnl.Realized, pnl.Unrealized)
+	if err != nil {
+		return false, err
+	}
+
+	inserted, err := result.RowsAffected()
+	return inserted > 0, err
+}
+
+// ReturnFilter narrows the recorded returns to a date range and optionally to one asset or asset type.
+type ReturnFilter struct {
+	From      time.Time
+	To        time.Time
+	AssetID   *int
+	AssetType string
 }
 
-func (r *AssetReturnHistoryRepository) GetMonthlyReturns(ctx context.Context) ([]MonthlyReturn, error) {
+// GetAssetReturns returns the recorded daily P&L of the assets matching filter, ordered by asset and date.
+func (r *AssetReturnHistoryRepository) GetAssetReturns(ctx context.Context, filter ReturnFilter) ([]models.AssetReturn, error) {
 	query := `
-	WITH MonthlyReturns AS (
-	    SELECT
-	        asset_id,
-	        DATE_TRUNC('month', date) AS month_start,
-	        MIN(date) AS first_date,
-	        MAX(date) AS last_date
-	    FROM asset_returns
-	    GROUP BY asset_id, DATE_TRUNC('month', date)
-	), FirstLastReturns AS (
-	    SELECT
-	        mr.asset_id,
-	        EXTRACT(YEAR FROM mr.month_start) AS year,
-	        EXTRACT(MONTH FROM mr.month_start) AS month,
-	        ar1.returns AS first_return,
-	        ar2.returns AS last_return
-	    FROM MonthlyReturns mr
-	    JOIN asset_returns ar1 ON mr.asset_id = ar1.asset_id AND mr.first_date = ar1.date
-	    JOIN asset_returns ar2 ON mr.asset_id = ar2.asset_id AND mr.last_date = ar2.date
-	)
-	SELECT
-	    flr.asset_id,
-	    a.name AS asset_name,
-	    flr.year,
-	    flr.month,
-	    flr.first_return,
-	    flr.last_return
-	FROM FirstLastReturns flr
-	JOIN assets a ON flr.asset_id = a.id
-	ORDER BY flr.year DESC, flr.month DESC, flr.asset_id;
-	`
+		SELECT ar.id, ar.asset_id, ar.date, ar.returns, ar.realized, ar.unrealized
+		FROM asset_returns ar
+		JOIN assets a ON a.id = ar.asset_id
+		WHERE ar.date BETWEEN $1::date AND $2::date
+		  AND ($3::int IS NULL OR ar.asset_id = $3)
+		  AND ($4 = '' OR a.type = $4)
+		  AND ($5::int IS NULL OR ar.owner_id = $5)
+		  AND ($6::int IS NULL OR a.portfolio_id = $6)
+		ORDER BY ar.asset_id, ar.date, ar.id`
 
-	rows, err := r.DB.QueryContext(ctx, query)
+	rows, err := r.DB.QueryContext(ctx, query, filter.From, filter.To, filter.AssetID, filter.AssetType, owner(ctx), portfolio(ctx))
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var returns []MonthlyReturn
+	var returns []models.AssetReturn
 	for rows.Next() {
-		var mr MonthlyReturn
-		if err := rows.Scan(&mr.AssetID, &mr.AssetName, &mr.Year, &mr.Month, &mr.FirstReturn, &mr.LastReturn); err != nil {
+		var ar models.AssetReturn
+		if err := rows.Scan(&ar.ID, &ar.AssetID, &ar.Date, &ar.Returns, &ar.Realized, &ar.Unrealized); err != nil {
 			return nil, err
 		}
-		returns = append(returns, mr)
+		returns = append(returns, ar)
 	}
-	return returns, nil
+	return returns, rows.Err()
 }
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type SnapshotRepository struct {
+	DB *sql.DB
+}
+
+func NewSnapshotRepository(db *sql.DB) *SnapshotRepository {
+	return &SnapshotRepository{DB: db}
+}
+
+// UpsertSnapshot stores a snapshot, replacing the one already taken of the same asset, or of the
+// same owner's portfolio or portfolios, on the same day.
+func (r *SnapshotRepository) UpsertSnapshot(ctx context.Context, s models.Snapshot) error {
+	_, err := r.insertSnapshot(ctx, s, `DO UPDATE SET
+			currency = EXCLUDED.currency,
+			quantity = EXCLUDED.quantity,
+			price = EXCLUDED.price,
+			market_value = EXCLUDED.market_value,
+			cost_basis = EXCLUDED.cost_basis,
+			realized = EXCLUDED.realized,
+			unrealized = EXCLUDED.unrealized,
+			base_currency = EXCLUDED.base_currency,
+			base_market_value = EXCLUDED.base_market_value,
+			base_cost_basis = EXCLUDED.base_cost_basis,
+			base_realized = EXCLUDED.base_realized,
+			base_unrealized = EXCLUDED.base_unrealized`)
+	return err
+}
+
+// InsertSnapshotIfMissing stores a snapshot of a past day unless one was already taken,
+// reporting whether it was written.
+func (r *SnapshotRepository) InsertSnapshotIfMissing(ctx context.Context, s models.Snapshot) (bool, error) {
+	return r.insertSnapshot(ctx, s, `DO NOTHING`)
+}
+
+func (r *SnapshotRepository) insertSnapshot(ctx context.Context, s models.Snapshot, onConflict string) (bool, error) {
+	query := `
+		INSERT INTO snapshots (date, asset_id, currency, quantity, price, market_value, cost_basis, realized, unrealized,
+			base_currency, base_market_value, base_cost_basis, base_realized, base_unrealized, owner_id,
+			portfolio_id)
+		VALUES ($1::date, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+		ON CONFLICT (date, COALESCE(owner_id, 0), COALESCE(portfolio_id, 0), COALESCE(asset_id, 0)) ` + onConflict
+
+	result, err := r.DB.ExecContext(ctx, query,
+		s.Date, s.AssetID, s.Currency, s.Quantity, s.Price, s.MarketValue, s.CostBasis, s.PnL.Realized, s.PnL.Unrealized,
+		s.BaseCurrency, s.BaseMarketValue, s.BaseCostBasis, s.BasePnL.Realized, s.BasePnL.Unrealized, s.OwnerID,
+		s.PortfolioID)
+	if err != nil {
+		return false, err
+	}
+
+	written, err := result.RowsAffected()
+	return written > 0, err
+}
+
+// GetSnapshots returns the snapshots of an asset, or when assetID is nil the totals of the
+// portfolio the context is narrowed to or across all portfolios, between from and to. interval is a date_trunc unit ('day', 'week' or 'month'); only the last
+// snapshot of each interval is returned.
+func (r *SnapshotRepository) GetSnapshots(ctx context.Context, assetID *int, from, to time.Time, interval string) ([]models.Snapshot, error) {
+	query := `
+		SELECT DISTINCT ON (DATE_TRUNC($4, date))
+			date, asset_id, portfolio_id, currency, quantity, price, market_value, cost_basis, realized, unrealized,
+			base_currency, base_market_value, base_cost_basis, base_realized, base_unrealized
+		FROM snapshots
+		WHERE asset_id IS NOT DISTINCT FROM $1 AND date BETWEEN $2::date AND $3::date
+		  AND ($5::int IS NULL OR owner_id = $5)
+		  AND ($1::int IS NOT NULL OR portfolio_id IS NOT DISTINCT FROM $6)
+		ORDER BY DATE_TRUNC($4, date), date DESC`
+
+	rows, err := r.DB.QueryContext(ctx, query, assetID, from, to, interval, owner(ctx), portfolio(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []models.Snapshot
+	for rows.Next() {
+		var s models.Snapshot
+		var realized, unrealized, baseRealized, baseUnrealized float64
+		err := rows.Scan(&s.Date, &s.AssetID, &s.PortfolioID, &s.Currency, &s.Quantity, &s.Price, &s.MarketValue, &s.CostBasis,
+			&realized, &unrealized, &s.BaseCurrency, &s.BaseMarketValue, &s.BaseCostBasis, &baseRealized, &baseUnrealized)
+		if err != nil {
+			return nil, err
+		}
+
+		id := 0
+		if s.AssetID != nil {
+			id = *s.AssetID
+		}
+		s.PnL = models.NewPnL(id, realized, unrealized)
+		s.BasePnL = models.NewPnL(id, baseRealized, baseUnrealized)
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type TargetRepository struct {
+	DB *sql.DB
+}
+
+func NewTargetRepository(db *sql.DB) *TargetRepository {
+	return &TargetRepository{DB: db}
+}
+
+func (r *TargetRepository) AddTarget(ctx context.Context, target *models.Target) error {
+	query := `
+		INSERT INTO targets (asset_type, asset_id, percent, owner_id)
+		VALUES (NULLIF($1, ''), $2, $3, $4)
+		RETURNING id, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, target.AssetType, target.AssetID, target.Percent, owner(ctx)).
+		Scan(&target.ID, &target.CreatedAt)
+}
+
+func (r *TargetRepository) UpdateTarget(ctx context.Context, target *models.Target) error {
+	query := `
+		UPDATE targets SET asset_type = NULLIF($1, ''), asset_id = $2, percent = $3
+		WHERE id = $4 AND ($5::int IS NULL OR owner_id = $5)`
+
+	result, err := r.DB.ExecContext(ctx, query, target.AssetType, target.AssetID, target.Percent, target.ID, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+func (r *TargetRepository) DeleteTarget(ctx context.Context, id int) error {
+	query := `DELETE FROM targets WHERE id = $1 AND ($2::int IS NULL OR owner_id = $2)`
+	result, err := r.DB.ExecContext(ctx, query, id, owner(ctx))
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
+
+func (r *TargetRepository) GetAllTargets(ctx context.Context) ([]*models.Target, error) {
+	query := `
+		SELECT id, COALESCE(asset_type, ''), asset_id, percent, created_at
+		FROM targets
+		WHERE $1::int IS NULL OR owner_id = $1
+		ORDER BY id`
+
+	rows, err := r.DB.QueryContext(ctx, query, owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []*models.Target
+	for rows.Next() {
+		var target models.Target
+		if err := rows.Scan(&target.ID, &target.AssetType, &target.AssetID, &target.Percent, &target.CreatedAt); err != nil {
+			return nil, err
+		}
+		targets = append(targets, &target)
+	}
+	return targets, rows.Err()
+}
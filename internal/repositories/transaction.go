@@ -0,0 +1,154 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// querier is implemented by both *sql.DB and *sql.Tx.
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type TransactionRepository struct {
+	DB *sql.DB
+}
+
+func NewTransactionRepository(db *sql.DB) *TransactionRepository {
+	return &TransactionRepository{DB: db}
+}
+
+// AddToLedger adds the transactions plan returns to the ledger of an asset in one database
+// transaction. The asset row is locked before the ledger is read and handed to plan, so
+// concurrent calls see each other's transactions and what plan checks cannot change before
+// its transactions are stored. While the ledger is still empty, plan is given opening, which
+// is inserted first, so the position of an asset created before the ledger existed is kept.
+func (r *TransactionRepository) AddToLedger(ctx context.Context, assetID int, opening []*models.Transaction,
+	plan func(ledger []*models.Transaction) ([]*models.Transaction, error)) error {
+	dbTx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer dbTx.Rollback()
+
+	if _, err := dbTx.ExecContext(ctx, `SELECT id FROM assets WHERE id = $1 FOR UPDATE`, assetID); err != nil {
+		return err
+	}
+	ledger, err := r.query(ctx, dbTx, `
+		SELECT id, asset_id, type, quantity, price, amount, fee, lot_id, date, note, created_at
+		FROM transactions
+		WHERE asset_id = $1
+		ORDER BY date, id`, assetID)
+	if err != nil {
+		return err
+	}
+	empty := len(ledger) == 0
+	if empty {
+		ledger = opening
+	}
+
+	txs, err := plan(ledger)
+	if err != nil {
+		return err
+	}
+	if empty && len(txs) > 0 {
+		txs = append(opening, txs...)
+	}
+
+	query := `
+		INSERT INTO transactions (asset_id, type, quantity, price, amount, fee, lot_id, date, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at`
+	for _, tx := range txs {
+		err := dbTx.QueryRowContext(ctx, query,
+			tx.AssetID, tx.Type, tx.Quantity, tx.Price, tx.Amount, tx.Fee, tx.LotID, tx.Date, tx.Note,
+		).Scan(&tx.ID, &tx.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dbTx.Commit()
+}
+
+func (r *TransactionRepository) GetTransactionsByAsset(ctx context.Context, assetID int) ([]*models.Transaction, error) {
+	query := `
+		SELECT t.id, t.asset_id, t.type, t.quantity, t.price, t.amount, t.fee, t.lot_id, t.date, t.note, t.created_at
+		FROM transactions t
+		JOIN assets a ON a.id = t.asset_id
+		WHERE t.asset_id = $1 AND ($2::int IS NULL OR a.owner_id = $2)
+		ORDER BY t.date, t.id`
+
+	return r.query(ctx, r.DB, query, assetID, owner(ctx))
+}
+
+// GetTransactionsByType returns the ledgers of all assets of the given type keyed by asset ID.
+func (r *TransactionRepository) GetTransactionsByType(ctx context.Context, assetType string) (map[int][]*models.Transaction, error) {
+	query := `
+		SELECT t.id, t.asset_id, t.type, t.quantity, t.price, t.amount, t.fee, t.lot_id, t.date, t.note, t.created_at
+		FROM transactions t
+		JOIN assets a ON a.id = t.asset_id
+		WHERE a.type = $1 AND ($2::int IS NULL OR a.owner_id = $2) AND ($3::int IS NULL OR a.portfolio_id = $3)
+		ORDER BY t.date, t.id`
+
+	txs, err := r.query(ctx, r.DB, query, assetType, owner(ctx), portfolio(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[int][]*models.Transaction)
+	for _, tx := range txs {
+		byAsset[tx.AssetID] = append(byAsset[tx.AssetID], tx)
+	}
+	return byAsset, nil
+}
+
+// GetAllTransactions returns the ledgers of all assets keyed by asset ID.
+func (r *TransactionRepository) GetAllTransactions(ctx context.Context) (map[int][]*models.Transaction, error) {
+	query := `
+		SELECT t.id, t.asset_id, t.type, t.quantity, t.price, t.amount, t.fee, t.lot_id, t.date, t.note, t.created_at
+		FROM transactions t
+		JOIN assets a ON a.id = t.asset_id
+		WHERE ($1::int IS NULL OR a.owner_id = $1) AND ($2::int IS NULL OR a.portfolio_id = $2)
+		ORDER BY t.date, t.id`
+
+	txs, err := r.query(ctx, r.DB, query, owner(ctx), portfolio(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	byAsset := make(map[int][]*models.Transaction)
+	for _, tx := range txs {
+		byAsset[tx.AssetID] = append(byAsset[tx.AssetID], tx)
+	}
+	return byAsset, nil
+}
+
+// query reads transactions through db, which is either the repository's database or a
+// database transaction.
+func (r *TransactionRepository) query(ctx context.Context, db querier, query string, args ...any) ([]*models.Transaction, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var txs []*models.Transaction
+	for rows.Next() {
+		var tx models.Transaction
+		err := rows.Scan(&tx.ID, &tx.AssetID, &tx.Type, &tx.Quantity, &tx.Price, &tx.Amount,
+			&tx.Fee, &tx.LotID, &tx.Date, &tx.Note, &tx.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, &tx)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return txs, nil
+}
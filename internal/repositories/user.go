@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+type ownerKey struct{}
+
+// WithOwner scopes every repository query made with the returned context to the rows of
+// one user.
+func WithOwner(ctx context.Context, userID int) context.Context {
+	return context.WithValue(ctx, ownerKey{}, userID)
+}
+
+// OwnerFrom returns the user a context is scoped to.
+func OwnerFrom(ctx context.Context) (int, bool) {
+	userID, ok := ctx.Value(ownerKey{}).(int)
+	return userID, ok
+}
+
+// owner is the query argument for the caller's user ID, NULL for the background jobs and
+// command line tools, which work across every user.
+func owner(ctx context.Context) *int {
+	if userID, ok := OwnerFrom(ctx); ok {
+		return &userID
+	}
+	return nil
+}
+
+type UserRepository struct {
+	DB *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{DB: db}
+}
+
+func (r *UserRepository) AddUser(ctx context.Context, user *models.User) error {
+	query := `
+		INSERT INTO users (email, password_hash)
+		VALUES (LOWER($1), $2)
+		RETURNING id, email, created_at`
+
+	return r.DB.QueryRowContext(ctx, query, user.Email, user.PasswordHash).
+		Scan(&user.ID, &user.Email, &user.CreatedAt)
+}
+
+// AdoptOwnerless hands the rows created before there were accounts to one user and returns
+// how many rows it moved. Registration never does this, so the first person to reach a
+// fresh deployment cannot take over its existing portfolio.
+func (r *UserRepository) AdoptOwnerless(ctx context.Context, userID int) (int64, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var adopted int64
+	for _, table := range []string{"assets", "asset_returns", "snapshots", "targets", "benchmarks"} {
+		result, err := tx.ExecContext(ctx, `UPDATE `+table+` SET owner_id = $1 WHERE owner_id IS NULL`, userID)
+		if err != nil {
+			return 0, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		adopted += rows
+	}
+
+	return adopted, tx.Commit()
+}
+
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	query := `SELECT id, email, password_hash, created_at FROM users WHERE email = LOWER($1)`
+
+	var user models.User
+	err := r.DB.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id int) (*models.User, error) {
+	query := `SELECT id, email, password_hash, created_at FROM users WHERE id = $1`
+
+	var user models.User
+	err := r.DB.QueryRowContext(ctx, query, id).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) AddSession(ctx context.Context, tokenHash string, userID int, expiresAt time.Time) error {
+	query := `INSERT INTO sessions (token_hash, user_id, expires_at) VALUES ($1, $2, $3)`
+
+	_, err := r.DB.ExecContext(ctx, query, tokenHash, userID, expiresAt)
+	return err
+}
+
+// GetSessionUser returns the user of an unexpired session, or sql.ErrNoRows.
+func (r *UserRepository) GetSessionUser(ctx context.Context, tokenHash string) (*models.User, error) {
+	query := `
+		SELECT u.id, u.email, u.password_hash, u.created_at
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.token_hash = $1 AND s.expires_at > NOW()`
+
+	var user models.User
+	err := r.DB.QueryRowContext(ctx, query, tokenHash).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteSession ends a session along with any of the user's sessions that have expired.
+func (r *UserRepository) DeleteSession(ctx context.Context, tokenHash string) error {
+	query := `
+		DELETE FROM sessions
+		WHERE token_hash = $1
+		   OR (expires_at <= NOW() AND user_id = (SELECT user_id FROM sessions WHERE token_hash = $1))`
+
+	result, err := r.DB.ExecContext(ctx, query, tokenHash)
+	if err != nil {
+		return err
+	}
+
+	return expectRows(result)
+}
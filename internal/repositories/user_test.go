@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories/migrations"
+	"github.com/jagac/pfinance/pkg/migrate"
+)
+
+func TestOwner(t *testing.T) {
+	if got := owner(context.Background()); got != nil {
+		t.Errorf("owner() without a user = %d, want nil", *got)
+	}
+
+	ctx := WithOwner(context.Background(), 7)
+	if got := owner(ctx); got == nil || *got != 7 {
+		t.Errorf("owner() = %v, want 7", got)
+	}
+	if got, ok := OwnerFrom(WithOwner(ctx, 8)); !ok || got != 8 {
+		t.Errorf("OwnerFrom() = %d, %v, want 8, true", got, ok)
+	}
+}
+
+// TestOwnerIsolation needs a throwaway Postgres database, named by PFINANCE_TEST_DATABASE_URL,
+// which it migrates to the latest version.
+func TestOwnerIsolation(t *testing.T) {
+	dsn := os.Getenv("PFINANCE_TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("PFINANCE_TEST_DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := migrate.New(db, slog.New(slog.NewTextHandler(io.Discard, nil)), migrations.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := migrator.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	users := NewUserRepository(db)
+	assets := NewAssetRepository(db)
+	addOwner := func(name string) (context.Context, *models.Asset) {
+		user := &models.User{Email: fmt.Sprintf("%s-%d@example.com", name, time.Now().UnixNano()), PasswordHash: "x"}
+		if err := users.AddUser(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec(`DELETE FROM users WHERE id = $1`, user.ID) })
+
+		owned := WithOwner(ctx, user.ID)
+		if err := assets.AddAsset(owned, &models.Asset{Name: name, Type: "Gold", Amount: 1, Currency: "USD"}); err != nil {
+			t.Fatal(err)
+		}
+		all, err := assets.GetAllAssets(owned)
+		if err != nil || len(all) != 1 {
+			t.Fatalf("GetAllAssets() = %d assets, %v, want the one just added", len(all), err)
+		}
+		t.Cleanup(func() { assets.DeleteAsset(owned, all[0].ID) })
+		return owned, all[0]
+	}
+	alice, aliceAsset := addOwner("alice")
+	_, bobAsset := addOwner("bob")
+
+	if _, err := assets.GetAssetByID(alice, bobAsset.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("GetAssetByID() of another user's asset: err = %v, want sql.ErrNoRows", err)
+	}
+	bobAsset.Name = "taken"
+	if err := assets.UpdateAsset(alice, bobAsset); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("UpdateAsset() of another user's asset: err = %v, want sql.ErrNoRows", err)
+	}
+	if err := assets.DeleteAsset(alice, bobAsset.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("DeleteAsset() of another user's asset: err = %v, want sql.ErrNoRows", err)
+	}
+
+	all, err := assets.GetAllAssets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]int, len(all))
+	for i, asset := range all {
+		ids[i] = asset.ID
+	}
+	if !slices.Contains(ids, aliceAsset.ID) || !slices.Contains(ids, bobAsset.ID) {
+		t.Errorf("GetAllAssets() without an owner = %v, want both users' assets", ids)
+	}
+	if unchanged, err := assets.GetAssetByID(ctx, bobAsset.ID); err != nil || unchanged.Name != "bob" {
+		t.Errorf("another user's asset after the update: %v, %v, want it unchanged", unchanged, err)
+	}
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type AlertRouter struct {
+	handler        *handlers.AlertHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewAlertRouter(handler *handlers.AlertHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *AlertRouter {
+	return &AlertRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *AlertRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("POST /api/alerts/new", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CreateRule)))))
+	mux.Handle("GET /api/alerts/all", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetRules)))))
+	mux.Handle("PUT /api/alerts/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.UpdateRule)))))
+	mux.Handle("DELETE /api/alerts/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeleteRule)))))
+	return mux
+}
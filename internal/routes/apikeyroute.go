@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type APIKeyRouter struct {
+	handler        *handlers.APIKeyHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+// NewAPIKeyRouter takes an authMiddleware that only accepts session tokens, so that an API
+// key cannot be used to issue or revoke keys.
+func NewAPIKeyRouter(handler *handlers.APIKeyHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *APIKeyRouter {
+	return &APIKeyRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *APIKeyRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("POST /api/keys/new", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CreateKey)))))
+	mux.Handle("GET /api/keys/all", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetKeys)))))
+	mux.Handle("DELETE /api/keys/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeleteKey)))))
+	return mux
+}
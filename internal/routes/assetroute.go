@@ -10,20 +10,37 @@ type AssetRouter struct {
 	handler *handlers.AssetHandler
 	logMiddleware func(http.Handler) http.Handler
 	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
 }
 
-func NewAssetRouter(handler *handlers.AssetHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler) *AssetRouter {
+func NewAssetRouter(handler *handlers.AssetHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *AssetRouter {
 	return &AssetRouter{
 		handler: handler,
 		logMiddleware: logMiddleware,
 		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
 	}
 }
 
 func (r *AssetRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
-	mux.Handle("POST /api/assets/new", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.CreateAsset))))
-	mux.Handle("GET /api/assets/all", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.GetAssets))))
-	mux.Handle("GET /api/returns", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.GetReturns))))
-	mux.Handle("GET /api/returns/month", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.GetMonthlyReturns))))
+	mux.Handle("POST /api/assets/new", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CreateAsset)))))
+	mux.Handle("GET /api/assets/all", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetAssets)))))
+	mux.Handle("GET /api/assets/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetAsset)))))
+	mux.Handle("PUT /api/assets/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.UpdateAsset)))))
+	mux.Handle("PATCH /api/assets/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.PatchAsset)))))
+	mux.Handle("DELETE /api/assets/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeleteAsset)))))
+	mux.Handle("POST /api/assets/{id}/transactions", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.AddTransaction)))))
+	mux.Handle("GET /api/assets/{id}/transactions", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetTransactions)))))
+	mux.Handle("POST /api/assets/{id}/rates", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.AddInterestRate)))))
+	mux.Handle("GET /api/assets/{id}/rates", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetInterestRates)))))
+	mux.Handle("DELETE /api/assets/{id}/rates/{rateId}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeleteInterestRate)))))
+	mux.Handle("GET /api/assets/{id}/holding", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetHolding)))))
+	mux.Handle("GET /api/assets/{id}/bond", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetBondValuation)))))
+	mux.Handle("GET /api/returns", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetReturns)))))
+	mux.Handle("GET /api/returns/pnl", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetProfitAndLoss)))))
+	mux.Handle("GET /api/returns/valuations", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetValuations)))))
+	mux.Handle("GET /api/portfolio/summary", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetPortfolioSummary)))))
+	mux.Handle("GET /api/snapshots", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetSnapshots)))))
 	return mux
 }
@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type AuthRouter struct {
+	handler        *handlers.AuthHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewAuthRouter(handler *handlers.AuthHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *AuthRouter {
+	return &AuthRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// RegisterRoutes registers the account routes. Registering and logging in are the only
+// routes that do not need a session.
+func (r *AuthRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("POST /api/auth/register", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.Register))))
+	mux.Handle("POST /api/auth/login", r.corsMiddleware(r.logMiddleware(http.HandlerFunc(r.handler.Login))))
+	mux.Handle("POST /api/auth/logout", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.Logout)))))
+	mux.Handle("GET /api/auth/me", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetMe)))))
+	return mux
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type BenchmarkRouter struct {
+	handler        *handlers.BenchmarkHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewBenchmarkRouter(handler *handlers.BenchmarkHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *BenchmarkRouter {
+	return &BenchmarkRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *BenchmarkRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("GET /api/benchmarks", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CompareBenchmarks)))))
+	mux.Handle("POST /api/benchmarks/new", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CreateBenchmark)))))
+	mux.Handle("GET /api/benchmarks/all", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetBenchmarks)))))
+	mux.Handle("DELETE /api/benchmarks/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeleteBenchmark)))))
+	return mux
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type DividendRouter struct {
+	handler        *handlers.DividendHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewDividendRouter(handler *handlers.DividendHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *DividendRouter {
+	return &DividendRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *DividendRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("GET /api/dividends", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetDividends)))))
+	return mux
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type PerformanceRouter struct {
+	handler        *handlers.PerformanceHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewPerformanceRouter(handler *handlers.PerformanceHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *PerformanceRouter {
+	return &PerformanceRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *PerformanceRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("GET /api/performance", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetPerformance)))))
+	mux.Handle("GET /api/returns/history", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetReturnHistory)))))
+	return mux
+}
@@ -0,0 +1,33 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/jagac/pfinance/internal/handlers"
+)
+
+type PortfolioRouter struct {
+	handler        *handlers.PortfolioHandler
+	logMiddleware  func(http.Handler) http.Handler
+	corsMiddleware func(http.Handler) http.Handler
+	authMiddleware func(http.Handler) http.Handler
+}
+
+func NewPortfolioRouter(handler *handlers.PortfolioHandler, logMiddleware func(http.Handler) http.Handler, corsMiddleware func(http.Handler) http.Handler,
+	authMiddleware func(http.Handler) http.Handler) *PortfolioRouter {
+	return &PortfolioRouter{
+		handler:        handler,
+		logMiddleware:  logMiddleware,
+		corsMiddleware: corsMiddleware,
+		authMiddleware: authMiddleware,
+	}
+}
+
+func (r *PortfolioRouter) RegisterRoutes(mux *http.ServeMux) *http.ServeMux {
+	mux.Handle("POST /api/portfolios/new", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.CreatePortfolio)))))
+	mux.Handle("GET /api/portfolios/all", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetPortfolios)))))
+	mux.Handle("GET /api/portfolios/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.GetPortfolio)))))
+	mux.Handle("PUT /api/portfolios/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.UpdatePortfolio)))))
+	mux.Handle("DELETE /api/portfolios/{id}", r.corsMiddleware(r.logMiddleware(r.authMiddleware(http.HandlerFunc(r.handler.DeletePortfolio)))))
+	return mux
+}
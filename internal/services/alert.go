@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+	"github.com/jagac/pfinance/pkg/notification"
+)
+
+// errNothingToCheck is returned by the check of a rule that has nothing to compare, such as
+// a price alert on an asset no longer held. The rule is left as it is until there is.
+var errNothingToCheck = errors.New("nothing to check")
+
+// defaultAlertCooldown is used for rules created without a cooldown, in minutes.
+const defaultAlertCooldown = 24 * 60
+
+// AlertService manages alert rules and notifies their owners when the rules fire.
+type AlertService struct {
+	Repo          *repositories.AlertRepository
+	userRepo      *repositories.UserRepository
+	assetRepo     *repositories.AssetRepository
+	portfolioRepo *repositories.PortfolioRepository
+	snapshotRepo  *repositories.SnapshotRepository
+	returns       *ReturnsCalculator
+	performance   *PerformanceService
+	targets       *TargetService
+	notifier      notification.Notifier
+}
+
+func NewAlertService(repo *repositories.AlertRepository,
+	userRepo *repositories.UserRepository,
+	assetRepo *repositories.AssetRepository,
+	portfolioRepo *repositories.PortfolioRepository,
+	snapshotRepo *repositories.SnapshotRepository,
+	returns *ReturnsCalculator,
+	performance *PerformanceService,
+	targets *TargetService,
+	notifier notification.Notifier) *AlertService {
+	return &AlertService{Repo: repo, userRepo: userRepo, assetRepo: assetRepo, portfolioRepo: portfolioRepo, snapshotRepo: snapshotRepo,
+		returns: returns, performance: performance, targets: targets, notifier: notifier}
+}
+
+func (s *AlertService) CreateRule(ctx context.Context, rule *models.AlertRule) error {
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+	return s.Repo.AddRule(ctx, rule)
+}
+
+func (s *AlertService) UpdateRule(ctx context.Context, rule *models.AlertRule) error {
+	if err := s.validate(ctx, rule); err != nil {
+		return err
+	}
+	return s.Repo.UpdateRule(ctx, rule)
+}
+
+func (s *AlertService) DeleteRule(ctx context.Context, id int) error {
+	return s.Repo.DeleteRule(ctx, id)
+}
+
+// validate checks that a rule is of a known kind, names an asset when its kind needs one
+// and only refers to the caller's own asset, portfolio and email address.
+func (s *AlertService) validate(ctx context.Context, rule *models.AlertRule) error {
+	switch rule.Kind {
+	case models.AlertPriceAbove, models.AlertPriceBelow:
+		if rule.AssetID == nil {
+			return errors.New("a price alert needs an asset ID")
+		}
+	case models.AlertDailyChange:
+	case models.AlertDrawdown, models.AlertDrift:
+		if rule.AssetID != nil {
+			return fmt.Errorf("a %s alert is for a portfolio, not an asset", rule.Kind)
+		}
+	default:
+		return fmt.Errorf("unknown alert kind %q", rule.Kind)
+	}
+	if rule.Threshold <= 0 {
+		return errors.New("alert threshold must be positive")
+	}
+	if rule.CooldownMinutes < 0 {
+		return errors.New("alert cooldown cannot be negative")
+	}
+	if rule.CooldownMinutes == 0 {
+		rule.CooldownMinutes = defaultAlertCooldown
+	}
+
+	if rule.Recipient != "" {
+		if err := s.validateRecipient(ctx, rule.Recipient); err != nil {
+			return err
+		}
+	}
+	if rule.AssetID != nil {
+		if _, err := s.assetRepo.GetAssetByID(ctx, *rule.AssetID); err != nil {
+			return fmt.Errorf("asset %d: %v", *rule.AssetID, err)
+		}
+	}
+	if rule.PortfolioID != nil {
+		if _, err := s.portfolioRepo.GetPortfolioByID(ctx, *rule.PortfolioID); err != nil {
+			return fmt.Errorf("portfolio %d: %v", *rule.PortfolioID, err)
+		}
+	}
+	return nil
+}
+
+// validateRecipient checks that an alert recipient is the caller's own email, so rules cannot
+// make the server's mail account write to other addresses.
+func (s *AlertService) validateRecipient(ctx context.Context, recipient string) error {
+	address, err := mail.ParseAddress(recipient)
+	if err != nil {
+		return fmt.Errorf("invalid alert recipient: %v", err)
+	}
+	userID, ok := repositories.OwnerFrom(ctx)
+	if !ok {
+		return errors.New("alert recipient needs a user")
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(address.Address, user.Email) {
+		return errors.New("alerts can only be sent to your own email")
+	}
+	return nil
+}
+
+// Evaluate checks every enabled rule against current prices and recorded history, as its
+// owner sees them, and notifies the rules whose condition has started to hold. Rules on an
+// asset without a current valuation, e.g. one sold in full, are skipped. A rule that cannot
+// be checked for another reason is reported in the returned error without holding up the others.
+func (s *AlertService) Evaluate(ctx context.Context, now time.Time) error {
+	rules, err := s.Repo.GetEnabledRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rule := range rules {
+		if err := s.evaluate(ctx, rule, now); err != nil {
+			errs = append(errs, fmt.Errorf("alert %d: %w", rule.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *AlertService) evaluate(ctx context.Context, rule *models.AlertRule, now time.Time) error {
+	scoped := repositories.WithOwner(ctx, rule.OwnerID)
+	if rule.PortfolioID != nil {
+		scoped = repositories.WithPortfolio(scoped, *rule.PortfolioID)
+	}
+
+	holds, message, err := s.check(scoped, rule, now)
+	if errors.Is(err, errNothingToCheck) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	notify, rearm := transition(rule, holds, now)
+	if rearm {
+		return s.Repo.MarkRule(ctx, rule.ID, false, false)
+	}
+	if !notify {
+		return nil
+	}
+
+	subject := fmt.Sprintf("pfinance alert: %s", rule.Kind)
+	if err := s.notifier.Send(ctx, rule.Recipient, subject, message); err != nil {
+		return err
+	}
+	return s.Repo.MarkRule(ctx, rule.ID, true, true)
+}
+
+// transition decides what the outcome of a check means for a rule: notify when its condition
+// has started to hold and the cooldown since the last notification has passed, and re-arm it
+// once the condition no longer holds.
+func transition(rule *models.AlertRule, holds bool, now time.Time) (notify, rearm bool) {
+	if !holds {
+		return false, rule.Active
+	}
+	if rule.Active {
+		return false, false
+	}
+	cooldown := time.Duration(rule.CooldownMinutes) * time.Minute
+	if rule.LastNotifiedAt != nil && now.Sub(*rule.LastNotifiedAt) < cooldown {
+		return false, false
+	}
+	return true, false
+}
+
+// check reports whether the condition of a rule holds, with a message describing it.
+func (s *AlertService) check(ctx context.Context, rule *models.AlertRule, now time.Time) (bool, string, error) {
+	switch rule.Kind {
+	case models.AlertPriceAbove, models.AlertPriceBelow:
+		return s.checkPrice(ctx, rule)
+	case models.AlertDailyChange:
+		return s.checkDailyChange(ctx, rule, now)
+	case models.AlertDrawdown:
+		return s.checkDrawdown(ctx, rule, now)
+	case models.AlertDrift:
+		return s.checkDrift(ctx, rule)
+	}
+	return false, "", fmt.Errorf("unknown alert kind %q", rule.Kind)
+}
+
+func (s *AlertService) checkPrice(ctx context.Context, rule *models.AlertRule) (bool, string, error) {
+	asset, err := s.assetRepo.GetAssetByID(ctx, *rule.AssetID)
+	if err != nil {
+		return false, "", err
+	}
+	valuations, err := s.returns.Valuations(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	valuation, ok := valuations[asset.ID]
+	if !ok {
+		return false, "", errNothingToCheck
+	}
+
+	holds := valuation.Price > rule.Threshold
+	direction := "above"
+	if rule.Kind == models.AlertPriceBelow {
+		holds = valuation.Price < rule.Threshold
+		direction = "below"
+	}
+	message := fmt.Sprintf("%s is at %.2f %s, %s %.2f", asset.Name, valuation.Price, valuation.Currency, direction, rule.Threshold)
+	return holds, message, nil
+}
+
+// checkDailyChange compares the current P&L of an asset, or of the portfolio, with the last
+// snapshot taken before today, as a percentage of the market value then.
+func (s *AlertService) checkDailyChange(ctx context.Context, rule *models.AlertRule, now time.Time) (bool, string, error) {
+	today := day(now)
+	snapshots, err := s.snapshotRepo.GetSnapshots(ctx, rule.AssetID, today.AddDate(0, 0, -7), today.AddDate(0, 0, -1), "day")
+	if err != nil {
+		return false, "", err
+	}
+	if len(snapshots) == 0 {
+		return false, "", nil
+	}
+	previous := snapshots[len(snapshots)-1]
+	if previous.BaseMarketValue <= 0 {
+		return false, "", nil
+	}
+
+	valuations, err := s.returns.Valuations(ctx)
+	if err != nil {
+		return false, "", err
+	}
+	var pnl float64
+	name := "Your portfolio"
+	if rule.AssetID != nil {
+		asset, err := s.assetRepo.GetAssetByID(ctx, *rule.AssetID)
+		if err != nil {
+			return false, "", err
+		}
+		pnl = valuations[asset.ID].BasePnL.Total
+		name = asset.Name
+	} else {
+		for _, v := range valuations {
+			pnl += v.BasePnL.Total
+		}
+	}
+
+	change := (pnl - previous.BasePnL.Total) / previous.BaseMarketValue * 100
+	message := fmt.Sprintf("%s moved %+.2f%% since %s", name, change, previous.Date.Format(time.DateOnly))
+	return math.Abs(change) >= rule.Threshold, message, nil
+}
+
+func (s *AlertService) checkDrawdown(ctx context.Context, rule *models.AlertRule, now time.Time) (bool, string, error) {
+	points, err := s.performance.PortfolioSeries(ctx, time.Time{}, now)
+	if err != nil {
+		return false, "", err
+	}
+
+	depth := currentDrawdown(periodReturns(points)) * 100
+	message := fmt.Sprintf("Your portfolio is %.2f%% below its peak", depth)
+	return depth >= rule.Threshold, message, nil
+}
+
+// currentDrawdown is how far the growth of a series of period returns ends below its high,
+// as a fraction.
+func currentDrawdown(returns []periodReturn) float64 {
+	growth, high := 1.0, 1.0
+	for _, r := range returns {
+		growth *= 1 + r.Return
+		high = max(high, growth)
+	}
+	return 1 - growth/high
+}
+
+// checkDrift fires when any target is further from its percentage than the threshold.
+func (s *AlertService) checkDrift(ctx context.Context, rule *models.AlertRule) (bool, string, error) {
+	plan, err := s.targets.Rebalance(ctx, 0, 0, false)
+	if err != nil {
+		return false, "", err
+	}
+
+	var worst *models.RebalanceTrade
+	for i, trade := range plan.Trades {
+		if worst == nil || math.Abs(trade.Drift) > math.Abs(worst.Drift) {
+			worst = &plan.Trades[i]
+		}
+	}
+	if worst == nil {
+		return false, "", nil
+	}
+
+	target := worst.AssetType
+	if worst.AssetID != nil {
+		target = fmt.Sprintf("asset %d", *worst.AssetID)
+	}
+	message := fmt.Sprintf("%s is at %.2f%% of the portfolio against a target of %.2f%%",
+		target, worst.CurrentPercent, worst.TargetPercent)
+	return math.Abs(worst.Drift) >= rule.Threshold, message, nil
+}
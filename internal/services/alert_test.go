@@ -0,0 +1,62 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestTransition(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time { return ptrTime(now.Add(-d)) }
+
+	tests := []struct {
+		name       string
+		rule       models.AlertRule
+		holds      bool
+		wantNotify bool
+		wantRearm  bool
+	}{
+		{
+			name:       "fires when the condition starts to hold",
+			rule:       models.AlertRule{CooldownMinutes: 60},
+			holds:      true,
+			wantNotify: true,
+		},
+		{
+			name:  "stays quiet while the condition keeps holding",
+			rule:  models.AlertRule{CooldownMinutes: 60, Active: true, LastNotifiedAt: ago(10 * time.Hour)},
+			holds: true,
+		},
+		{
+			name:      "re-arms once the condition clears",
+			rule:      models.AlertRule{CooldownMinutes: 60, Active: true, LastNotifiedAt: ago(10 * time.Minute)},
+			wantRearm: true,
+		},
+		{
+			name: "nothing to do while cleared and armed",
+			rule: models.AlertRule{CooldownMinutes: 60, LastNotifiedAt: ago(10 * time.Minute)},
+		},
+		{
+			name:  "waits out the cooldown after re-arming",
+			rule:  models.AlertRule{CooldownMinutes: 60, LastNotifiedAt: ago(30 * time.Minute)},
+			holds: true,
+		},
+		{
+			name:       "fires again after the cooldown",
+			rule:       models.AlertRule{CooldownMinutes: 60, LastNotifiedAt: ago(61 * time.Minute)},
+			holds:      true,
+			wantNotify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notify, rearm := transition(&tt.rule, tt.holds, now)
+			if notify != tt.wantNotify || rearm != tt.wantRearm {
+				t.Errorf("transition() = notify %v, re-arm %v, want %v, %v", notify, rearm, tt.wantNotify, tt.wantRearm)
+			}
+		})
+	}
+}
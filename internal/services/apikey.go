@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// apiKeyPrefix marks a string as one of our API keys, so a leaked key is easy to search for.
+const apiKeyPrefix = "pfk_"
+
+// APIKeyService issues and checks the API keys users give their scripts.
+type APIKeyService struct {
+	Repo *repositories.APIKeyRepository
+}
+
+func NewAPIKeyService(repo *repositories.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{Repo: repo}
+}
+
+// CreateKey issues a key with the given name and scope, read-only unless asked otherwise.
+// The key itself is returned once, in key.Key.
+func (s *APIKeyService) CreateKey(ctx context.Context, key *models.APIKey) error {
+	key.Name = strings.TrimSpace(key.Name)
+	if key.Name == "" {
+		return errors.New("API key name is required")
+	}
+	switch key.Scope {
+	case "":
+		key.Scope = models.APIKeyRead
+	case models.APIKeyRead, models.APIKeyWrite:
+	default:
+		return errors.New("API key scope must be read or write")
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	key.Key = apiKeyPrefix + token
+	key.Prefix = key.Key[:len(apiKeyPrefix)+8]
+
+	return s.Repo.AddKey(ctx, key, hashToken(key.Key))
+}
+
+func (s *APIKeyService) DeleteKey(ctx context.Context, id int) error {
+	return s.Repo.DeleteKey(ctx, id)
+}
+
+// Authenticate returns the user and scope of a key and records that it was used.
+func (s *APIKeyService) Authenticate(ctx context.Context, key string) (int, string, error) {
+	userID, scope, err := s.Repo.UseKey(ctx, hashToken(key))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, "", ErrUnauthenticated
+	}
+	return userID, scope, err
+}
@@ -2,23 +2,51 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"slices"
+	"time"
 
 	"github.com/jagac/pfinance/internal/models"
 	"github.com/jagac/pfinance/internal/repositories"
 )
 
+// ErrInvalidAsset is wrapped by the errors returned for an asset or transaction that fails
+// validation, as opposed to one that could not be loaded or stored.
+var ErrInvalidAsset = errors.New("invalid asset")
+
+// assetTypes are the asset types that are valued and priced.
+var assetTypes = []string{"Stock", "Gold", "Crypto", "Bond", "Savings"}
+
+// transactionTypes are the transactions that fit each asset type: market assets are traded
+// in units, savings accounts in amounts, and bonds pay coupons rather than dividends.
+var transactionTypes = map[string][]string{
+	"Stock":   {models.TransactionBuy, models.TransactionSell, models.TransactionDividend, models.TransactionFee},
+	"Gold":    {models.TransactionBuy, models.TransactionSell, models.TransactionDividend, models.TransactionFee},
+	"Crypto":  {models.TransactionBuy, models.TransactionSell, models.TransactionDividend, models.TransactionFee},
+	"Savings": {models.TransactionDeposit, models.TransactionWithdrawal},
+	"Bond":    {models.TransactionBuy, models.TransactionSell, models.TransactionCoupon},
+}
+
 type AssetService struct {
-	Repo *repositories.AssetRepository
+	Repo          *repositories.AssetRepository
+	TxRepo        *repositories.TransactionRepository
+	RateRepo      *repositories.InterestRateRepository
+	PortfolioRepo *repositories.PortfolioRepository
+	// LotMethod is used for assets outside any portfolio
+	LotMethod LotMethod
 }
 
-func NewAssetService(repo *repositories.AssetRepository) *AssetService {
-	return &AssetService{Repo: repo}
+func NewAssetService(repo *repositories.AssetRepository, txRepo *repositories.TransactionRepository,
+	rateRepo *repositories.InterestRateRepository, portfolioRepo *repositories.PortfolioRepository,
+	lotMethod LotMethod) *AssetService {
+	return &AssetService{Repo: repo, TxRepo: txRepo, RateRepo: rateRepo, PortfolioRepo: portfolioRepo, LotMethod: lotMethod}
 }
 
 func (s *AssetService) CreateAsset(ctx context.Context, asset *models.Asset) error {
-	if asset.Name == "" {
-		return errors.New("asset name is required")
+	if err := s.validate(ctx, asset); err != nil {
+		return err
 	}
 	return s.Repo.AddAsset(ctx, asset)
 }
@@ -26,3 +54,188 @@ func (s *AssetService) CreateAsset(ctx context.Context, asset *models.Asset) err
 func (s *AssetService) GetAsset(ctx context.Context, id int) (*models.Asset, error) {
 	return s.Repo.GetAssetByID(ctx, id)
 }
+
+func (s *AssetService) UpdateAsset(ctx context.Context, asset *models.Asset) error {
+	if err := s.validate(ctx, asset); err != nil {
+		return err
+	}
+	return s.Repo.UpdateAsset(ctx, asset)
+}
+
+// validate checks that an asset is named, of a known type and that its portfolio belongs to the caller.
+func (s *AssetService) validate(ctx context.Context, asset *models.Asset) error {
+	if asset.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAsset)
+	}
+	if !slices.Contains(assetTypes, asset.Type) {
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidAsset, asset.Type)
+	}
+	if asset.PortfolioID != nil {
+		_, err := s.PortfolioRepo.GetPortfolioByID(ctx, *asset.PortfolioID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: portfolio %d not found", ErrInvalidAsset, *asset.PortfolioID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *AssetService) DeleteAsset(ctx context.Context, id int) error {
+	return s.Repo.DeleteAsset(ctx, id)
+}
+
+func (s *AssetService) AddTransaction(ctx context.Context, tx *models.Transaction) error {
+	asset, err := s.Repo.GetAssetByID(ctx, tx.AssetID)
+	if err != nil {
+		return err
+	}
+
+	switch tx.Type {
+	case models.TransactionBuy, models.TransactionSell:
+		if tx.Quantity <= 0 || tx.Price <= 0 {
+			return fmt.Errorf("%w: buy and sell transactions need a positive quantity and price", ErrInvalidAsset)
+		}
+	case models.TransactionDeposit, models.TransactionWithdrawal, models.TransactionDividend, models.TransactionFee,
+		models.TransactionCoupon:
+		if tx.Amount <= 0 {
+			return fmt.Errorf("%w: %s transactions need a positive amount", ErrInvalidAsset, tx.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unknown transaction type %q", ErrInvalidAsset, tx.Type)
+	}
+	if !slices.Contains(transactionTypes[asset.Type], tx.Type) {
+		return fmt.Errorf("%w: a %s asset cannot record %s transactions", ErrInvalidAsset, asset.Type, tx.Type)
+	}
+
+	if tx.LotID != nil && tx.Type != models.TransactionSell {
+		return fmt.Errorf("%w: only sell transactions can name a lot", ErrInvalidAsset)
+	}
+	if tx.LotID != nil && s.lotMethod(asset) != LotSpecific {
+		return fmt.Errorf("%w: lots are matched by %s, a sell cannot name a lot", ErrInvalidAsset, s.lotMethod(asset))
+	}
+
+	return s.recordFrom(ctx, asset, func(ledger []*models.Transaction) ([]*models.Transaction, error) {
+		if tx.Type == models.TransactionSell || tx.Type == models.TransactionWithdrawal {
+			if err := checkHeld(asset, ledger, tx, s.lotMethod(asset)); err != nil {
+				return nil, err
+			}
+		}
+		return []*models.Transaction{tx}, nil
+	})
+}
+
+// checkHeld checks that a sell or withdrawal takes no more than was held on its date, from
+// a lot that was open then, and that it leaves enough for the sells and withdrawals already
+// booked after it.
+func checkHeld(asset *models.Asset, ledger []*models.Transaction, tx *models.Transaction, method LotMethod) error {
+	i := slices.IndexFunc(ledger, func(t *models.Transaction) bool { return t.Date.After(tx.Date) })
+	if i < 0 {
+		i = len(ledger)
+	}
+	var holding models.Holding
+	if i > 0 {
+		holding = BuildHolding(asset, ledger[:i], method)
+	}
+
+	if tx.LotID != nil && !slices.ContainsFunc(holding.Lots, func(l models.Lot) bool { return l.TransactionID == *tx.LotID }) {
+		return fmt.Errorf("%w: lot %d is not an open lot of asset %d", ErrInvalidAsset, *tx.LotID, asset.ID)
+	}
+	// Allow for rounding left over from splitting lots
+	const tolerance = 1e-9
+	held := holding.Quantity + units(tx)
+	if held < -tolerance {
+		return fmt.Errorf("%w: cannot %s more than the %.4f held on %s", ErrInvalidAsset, tx.Type,
+			holding.Quantity, tx.Date.Format(time.DateOnly))
+	}
+
+	for _, later := range ledger[i:] {
+		if held += units(later); held < -tolerance {
+			return fmt.Errorf("%w: the %s on %s would leave too little for the %s on %s", ErrInvalidAsset,
+				tx.Type, tx.Date.Format(time.DateOnly), later.Type, later.Date.Format(time.DateOnly))
+		}
+	}
+	return nil
+}
+
+// units is how much a transaction adds to or, when negative, takes from the position.
+func units(tx *models.Transaction) float64 {
+	switch tx.Type {
+	case models.TransactionBuy:
+		return tx.Quantity
+	case models.TransactionSell:
+		return -tx.Quantity
+	case models.TransactionDeposit:
+		return tx.Amount
+	case models.TransactionWithdrawal:
+		return -tx.Amount
+	}
+	return 0
+}
+
+// record adds transactions to the ledger of an asset. An asset created before the ledger
+// existed has its opening transactions persisted along with the first of them, so adding
+// to its ledger does not drop the position they stand in for.
+func (s *AssetService) record(ctx context.Context, asset *models.Asset, txs ...*models.Transaction) error {
+	return s.recordFrom(ctx, asset, func([]*models.Transaction) ([]*models.Transaction, error) { return txs, nil })
+}
+
+// recordFrom adds the transactions plan derives from the ledger of an asset, as record does.
+// The asset is locked while plan runs, so the ledger it is given stays current until its
+// transactions are stored.
+func (s *AssetService) recordFrom(ctx context.Context, asset *models.Asset,
+	plan func(ledger []*models.Transaction) ([]*models.Transaction, error)) error {
+	return s.TxRepo.AddToLedger(ctx, asset.ID, openingTransactions(asset), plan)
+}
+
+// lotMethod is how sells of an asset are matched to its lots: the method of its portfolio,
+// or the default for assets outside any portfolio.
+func (s *AssetService) lotMethod(asset *models.Asset) LotMethod {
+	if asset.LotMethod != "" {
+		return LotMethod(asset.LotMethod)
+	}
+	return s.LotMethod
+}
+
+// ledger returns the transactions of an asset, or the opening transactions standing in
+// for them when it was created before the ledger existed.
+func (s *AssetService) ledger(ctx context.Context, asset *models.Asset) ([]*models.Transaction, error) {
+	txs, err := s.TxRepo.GetTransactionsByAsset(ctx, asset.ID)
+	if err != nil || len(txs) > 0 {
+		return txs, err
+	}
+	return openingTransactions(asset), nil
+}
+
+func (s *AssetService) GetTransactions(ctx context.Context, assetID int) ([]*models.Transaction, error) {
+	return s.TxRepo.GetTransactionsByAsset(ctx, assetID)
+}
+
+// GetHolding derives the current position of a single asset from its ledger.
+func (s *AssetService) GetHolding(ctx context.Context, asset *models.Asset) (models.Holding, error) {
+	txs, err := s.TxRepo.GetTransactionsByAsset(ctx, asset.ID)
+	if err != nil {
+		return models.Holding{}, err
+	}
+	return BuildHolding(asset, txs, s.lotMethod(asset)), nil
+}
+
+// GetHoldingsByType returns all assets of a type together with their positions keyed by asset ID.
+func (s *AssetService) GetHoldingsByType(ctx context.Context, assetType string) ([]*models.Asset, map[int]models.Holding, error) {
+	assets, err := s.Repo.GetAssetsByType(ctx, assetType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ledgers, err := s.TxRepo.GetTransactionsByType(ctx, assetType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	holdings := make(map[int]models.Holding, len(assets))
+	for _, asset := range assets {
+		holdings[asset.ID] = BuildHolding(asset, ledgers[asset.ID], s.lotMethod(asset))
+	}
+	return assets, holdings, nil
+}
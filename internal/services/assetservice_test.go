@@ -0,0 +1,100 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestCheckHeld(t *testing.T) {
+	lot := func(id int) *int { return &id }
+	withdraw := func(id int, amount float64) *models.Transaction {
+		tx := sell(id, 0, 0, 0, nil)
+		tx.Type, tx.Amount = models.TransactionWithdrawal, amount
+		return tx
+	}
+	deposit := func(id int, amount float64) *models.Transaction {
+		tx := buy(id, 0, 0, 0)
+		tx.Type, tx.Amount = models.TransactionDeposit, amount
+		return tx
+	}
+
+	tests := []struct {
+		name    string
+		ledger  []*models.Transaction
+		tx      *models.Transaction
+		method  LotMethod
+		wantErr bool
+	}{
+		{
+			name:   "sells what is held",
+			ledger: []*models.Transaction{buy(1, 10, 100, 0)},
+			tx:     sell(3, 10, 110, 0, nil),
+		},
+		{
+			name:    "sells more than is held",
+			ledger:  []*models.Transaction{buy(1, 10, 100, 0)},
+			tx:      sell(3, 11, 110, 0, nil),
+			wantErr: true,
+		},
+		{
+			name:    "sells before the buy",
+			ledger:  []*models.Transaction{buy(5, 10, 100, 0)},
+			tx:      sell(3, 5, 110, 0, nil),
+			wantErr: true,
+		},
+		{
+			name:    "back-dated sell leaves too little for a later sell",
+			ledger:  []*models.Transaction{buy(1, 10, 100, 0), sell(9, 10, 110, 0, nil)},
+			tx:      sell(5, 5, 105, 0, nil),
+			wantErr: true,
+		},
+		{
+			name:   "back-dated sell covered by a later buy",
+			ledger: []*models.Transaction{buy(1, 10, 100, 0), buy(7, 5, 100, 0), sell(9, 10, 110, 0, nil)},
+			tx:     sell(5, 5, 105, 0, nil),
+		},
+		{
+			name:    "names a lot bought after the sell",
+			ledger:  []*models.Transaction{buy(1, 10, 100, 0), buy(7, 10, 100, 0)},
+			tx:      sell(5, 5, 105, 0, lot(7)),
+			method:  LotSpecific,
+			wantErr: true,
+		},
+		{
+			name:   "names an open lot",
+			ledger: []*models.Transaction{buy(1, 10, 100, 0), buy(2, 10, 100, 0)},
+			tx:     sell(5, 5, 105, 0, lot(2)),
+			method: LotSpecific,
+		},
+		{
+			name:   "withdraws the balance",
+			ledger: []*models.Transaction{deposit(1, 1000), withdraw(2, 400)},
+			tx:     withdraw(3, 600),
+		},
+		{
+			name:    "withdraws more than the balance",
+			ledger:  []*models.Transaction{deposit(1, 1000), withdraw(2, 400)},
+			tx:      withdraw(3, 601),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = LotFIFO
+			}
+
+			err := checkHeld(&models.Asset{ID: 1, Type: "Stock"}, tt.ledger, tt.tx, method)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkHeld() error = %v, want error: %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAsset) {
+				t.Errorf("checkHeld() error = %v, want it to wrap ErrInvalidAsset", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrInvalidCredentials is returned for an unknown email or a wrong password, without
+	// telling which.
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	// ErrUnauthenticated is returned for a missing, unknown or expired session token.
+	ErrUnauthenticated = errors.New("not logged in")
+	// ErrEmailTaken is returned when registering an email that already has an account.
+	ErrEmailTaken = errors.New("email already registered")
+)
+
+const minPasswordLength = 8
+
+// AuthService registers users and issues the session tokens they authenticate with.
+type AuthService struct {
+	Repo       *repositories.UserRepository
+	SessionTTL time.Duration
+}
+
+func NewAuthService(repo *repositories.UserRepository, sessionTTL time.Duration) *AuthService {
+	return &AuthService{Repo: repo, SessionTTL: sessionTTL}
+}
+
+func (s *AuthService) Register(ctx context.Context, creds models.Credentials) (*models.User, error) {
+	if !strings.Contains(creds.Email, "@") {
+		return nil, errors.New("a valid email is required")
+	}
+	if len(creds.Password) < minPasswordLength {
+		return nil, errors.New("password must be at least 8 characters")
+	}
+
+	_, err := s.Repo.GetUserByEmail(ctx, strings.TrimSpace(creds.Email))
+	if err == nil {
+		return nil, ErrEmailTaken
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(creds.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Email: strings.TrimSpace(creds.Email), PasswordHash: string(hash)}
+	if err := s.Repo.AddUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Login checks a user's password and opens a session. Only a hash of the returned token
+// is stored.
+func (s *AuthService) Login(ctx context.Context, creds models.Credentials) (models.Session, error) {
+	user, err := s.Repo.GetUserByEmail(ctx, strings.TrimSpace(creds.Email))
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Session{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.Session{}, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(creds.Password)); err != nil {
+		return models.Session{}, ErrInvalidCredentials
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return models.Session{}, err
+	}
+	session := models.Session{Token: token, ExpiresAt: time.Now().Add(s.SessionTTL), User: user}
+
+	if err := s.Repo.AddSession(ctx, hashToken(session.Token), user.ID, session.ExpiresAt); err != nil {
+		return models.Session{}, err
+	}
+	return session, nil
+}
+
+// Authenticate returns the user a session token belongs to.
+func (s *AuthService) Authenticate(ctx context.Context, token string) (*models.User, error) {
+	user, err := s.Repo.GetSessionUser(ctx, hashToken(token))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUnauthenticated
+	}
+	return user, err
+}
+
+func (s *AuthService) Logout(ctx context.Context, token string) error {
+	return s.Repo.DeleteSession(ctx, hashToken(token))
+}
+
+// newToken returns 32 random bytes, hex encoded.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is the SHA-256 of a session token or API key, the form in which they are stored.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// Backfiller reconstructs the daily asset_returns rows, snapshots and price history of past
+// days, e.g. for an asset whose first purchase predates the day it was added or for days
+// missed while the service was down.
+type Backfiller struct {
+	assetRepo    *repositories.AssetRepository
+	assets       *AssetService
+	historicRepo *repositories.AssetReturnHistoryRepository
+	priceRepo    *repositories.PriceRepository
+	snapshotRepo *repositories.SnapshotRepository
+	converter    *CurrencyConverter
+	prices       *PriceRegistry
+}
+
+func NewBackfiller(assetRepo *repositories.AssetRepository,
+	assets *AssetService,
+	historicRepo *repositories.AssetReturnHistoryRepository,
+	priceRepo *repositories.PriceRepository,
+	snapshotRepo *repositories.SnapshotRepository,
+	converter *CurrencyConverter,
+	prices *PriceRegistry) *Backfiller {
+	return &Backfiller{assetRepo: assetRepo, assets: assets, historicRepo: historicRepo, priceRepo: priceRepo,
+		snapshotRepo: snapshotRepo, converter: converter, prices: prices}
+}
+
+// Backfill fills in every day between from and to for one asset, or for all assets
+// when assetID is 0, and returns how many asset_returns rows it wrote. Each asset also gets
+// its daily snapshots and, when all assets are backfilled, so do the portfolio totals. Days
+// that are already recorded are left untouched, so running it again over the same range is
+// harmless. An asset that fails does not stop the others; its errors are returned together.
+// Past values are converted into the base currency at today's exchange rates.
+func (b *Backfiller) Backfill(ctx context.Context, assetID int, from, to time.Time) (int, error) {
+	from, to = day(from), day(to)
+	if to.Before(from) {
+		return 0, fmt.Errorf("backfill range ends before it starts")
+	}
+
+	var assets []*models.Asset
+	var totals *snapshotTotals
+	if assetID != 0 {
+		asset, err := b.assetRepo.GetAssetByID(ctx, assetID)
+		if err != nil {
+			return 0, err
+		}
+		assets = append(assets, asset)
+	} else {
+		var err error
+		assets, err = b.assetRepo.GetAllAssets(ctx)
+		if err != nil {
+			return 0, err
+		}
+		totals = newSnapshotTotals(b.converter.Base)
+	}
+
+	written := 0
+	var errs []error
+	for _, asset := range assets {
+		n, err := b.backfillAsset(ctx, asset, from, to, totals)
+		written += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("backfilling %s: %w", asset.Name, err))
+			for date := from; totals != nil && !date.After(to); date = date.AddDate(0, 0, 1) {
+				totals.fail(asset, date)
+			}
+		}
+	}
+
+	if totals != nil {
+		for _, total := range totals.snapshots() {
+			if _, err := b.snapshotRepo.InsertSnapshotIfMissing(ctx, total); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return written, errors.Join(errs...)
+}
+
+// backfillAsset records the days of one asset, adding its values to totals unless nil.
+func (b *Backfiller) backfillAsset(ctx context.Context, asset *models.Asset, from, to time.Time, totals *snapshotTotals) (int, error) {
+	txs, err := b.assets.TxRepo.GetTransactionsByAsset(ctx, asset.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(txs) == 0 {
+		txs = openingTransactions(asset)
+	}
+	if len(txs) == 0 {
+		return 0, nil
+	}
+
+	// Nothing was held before the first transaction
+	if first := day(txs[0].Date); from.Before(first) {
+		from = first
+	}
+	if to.Before(from) {
+		return 0, nil
+	}
+
+	holdingOn := func(date time.Time) models.Holding {
+		i := slices.IndexFunc(txs, func(tx *models.Transaction) bool { return day(tx.Date).After(date) })
+		if i < 0 {
+			i = len(txs)
+		}
+		return BuildHolding(asset, txs[:i], b.assets.lotMethod(asset))
+	}
+
+	written := 0
+	// record stores the value of the asset on a day, and its P&L in asset_returns if returns is set
+	record := func(valuation models.Valuation, date time.Time, returns bool) error {
+		if returns {
+			inserted, err := b.historicRepo.InsertAssetReturnIfMissing(ctx, valuation.BasePnL, date)
+			if inserted {
+				written++
+			}
+			if err != nil {
+				return err
+			}
+		}
+		if _, err := b.snapshotRepo.InsertSnapshotIfMissing(ctx, snapshotOf(asset, valuation, date)); err != nil {
+			return err
+		}
+		if totals != nil {
+			totals.add(asset, valuation, date)
+		}
+		return nil
+	}
+
+	switch asset.Type {
+	case "Stock", "Gold", "Crypto":
+		// A week of history before from covers the last close before a weekend or holiday
+		quotes, err := b.prices.History(ctx, asset.Type, QuoteSymbol(asset), b.converter.Base, from.AddDate(0, 0, -7), to)
+		if err != nil {
+			return 0, err
+		}
+
+		// Days the market was closed are valued at the last close but have no return of their own
+		i := 0
+		var last *models.Quote
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			traded := false
+			for ; i < len(quotes) && !quotes[i].Timestamp.After(date); i++ {
+				last = &quotes[i]
+				traded = !last.Timestamp.Before(date)
+			}
+			if last == nil {
+				continue
+			}
+			if traded {
+				if err := b.priceRepo.InsertQuote(ctx, asset.Type, *last); err != nil {
+					return written, err
+				}
+			}
+			valuation, err := b.converter.Valuate(asset, holdingOn(date), *last)
+			if err != nil {
+				return written, err
+			}
+			if err := record(valuation, date, traded); err != nil {
+				return written, err
+			}
+		}
+
+	case "Bond":
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			holding := holdingOn(date)
+			value, err := ValueBond(asset, holding, date)
+			if err != nil {
+				return written, err
+			}
+			valuation, err := b.converter.Valuate(asset, holding, value.Quote(asset.Currency))
+			if err != nil {
+				return written, err
+			}
+			if err := record(valuation, date, true); err != nil {
+				return written, err
+			}
+		}
+
+	case "Savings":
+		rates, err := b.assets.RateRepo.GetRatesByAsset(ctx, asset.ID)
+		if err != nil {
+			return 0, err
+		}
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			holding := holdingOn(date)
+			interest, ok := accruedInterest(asset, txs, rates, date)
+			if !ok {
+				continue
+			}
+			valuation, err := b.converter.valuation(asset, holding, 1, models.NewPnL(asset.ID, 0, interest))
+			if err != nil {
+				return written, err
+			}
+			if err := record(valuation, date, true); err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// ErrInvalidTicker is returned for a benchmark ticker that is not a plain market symbol.
+var ErrInvalidTicker = errors.New("invalid ticker")
+
+// tickerPattern matches market symbols such as AAPL, BRK.B, ^GSPC or SPY.US. Benchmark
+// tickers come from users and end up in the shared prices table and in provider URLs.
+var tickerPattern = regexp.MustCompile(`^[A-Za-z0-9^][A-Za-z0-9.^=_-]{0,19}$`)
+
+// validateTicker checks that a benchmark ticker is a plain market symbol.
+func validateTicker(ticker string) error {
+	if !tickerPattern.MatchString(ticker) {
+		return fmt.Errorf("%w %q", ErrInvalidTicker, ticker)
+	}
+	return nil
+}
+
+// BenchmarkService manages the index tickers the portfolio is compared against.
+type BenchmarkService struct {
+	Repo        *repositories.BenchmarkRepository
+	performance *PerformanceService
+	priceRepo   *repositories.PriceRepository
+	prices      *PriceRegistry
+}
+
+func NewBenchmarkService(repo *repositories.BenchmarkRepository,
+	performance *PerformanceService,
+	priceRepo *repositories.PriceRepository,
+	prices *PriceRegistry) *BenchmarkService {
+	return &BenchmarkService{Repo: repo, performance: performance, priceRepo: priceRepo, prices: prices}
+}
+
+func (s *BenchmarkService) CreateBenchmark(ctx context.Context, benchmark *models.Benchmark) error {
+	benchmark.Ticker = strings.TrimSpace(benchmark.Ticker)
+	if benchmark.Ticker == "" {
+		return errors.New("benchmark ticker is required")
+	}
+	if err := validateTicker(benchmark.Ticker); err != nil {
+		return err
+	}
+	return s.Repo.AddBenchmark(ctx, benchmark)
+}
+
+func (s *BenchmarkService) DeleteBenchmark(ctx context.Context, id int) error {
+	return s.Repo.DeleteBenchmark(ctx, id)
+}
+
+// Compare returns the cumulative time-weighted return of the portfolio between from and to
+// next to the cumulative return of every configured benchmark on the same days. A benchmark
+// starts on the first day it has a close for, measured from its close on that day.
+func (s *BenchmarkService) Compare(ctx context.Context, from, to time.Time) (models.BenchmarkComparison, error) {
+	benchmarks, err := s.Repo.GetAllBenchmarks(ctx)
+	if err != nil {
+		return models.BenchmarkComparison{}, err
+	}
+	points, err := s.performance.PortfolioSeries(ctx, from, to)
+	if err != nil {
+		return models.BenchmarkComparison{}, err
+	}
+
+	comparison := models.BenchmarkComparison{
+		From:       from,
+		To:         to,
+		Portfolio:  []models.CumulativeReturn{},
+		Benchmarks: make(map[string][]models.CumulativeReturn),
+	}
+	if len(points) == 0 {
+		return comparison, nil
+	}
+
+	comparison.Portfolio = append(comparison.Portfolio, models.CumulativeReturn{Date: points[0].Date})
+	growth := 1.0
+	for _, r := range periodReturns(points) {
+		growth *= 1 + r.Return
+		comparison.Portfolio = append(comparison.Portfolio, models.CumulativeReturn{Date: r.Date, Return: growth - 1})
+	}
+
+	for _, benchmark := range benchmarks {
+		closes, err := dailyCloses(ctx, s.priceRepo, s.prices, "Stock", benchmark.Ticker, s.performance.converter.Base, points[0].Date, to)
+		if err != nil {
+			return models.BenchmarkComparison{}, err
+		}
+
+		series := []models.CumulativeReturn{}
+		start := 0.0
+		for _, point := range points {
+			price, ok := closeOn(closes, point.Date)
+			if !ok || price <= 0 {
+				continue
+			}
+			if start == 0 {
+				start = price
+			}
+			series = append(series, models.CumulativeReturn{Date: point.Date, Return: price/start - 1})
+		}
+		comparison.Benchmarks[benchmark.Ticker] = series
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateTicker(t *testing.T) {
+	tests := []struct {
+		ticker string
+		valid  bool
+	}{
+		{"AAPL", true},
+		{"BRK.B", true},
+		{"^GSPC", true},
+		{"SPY.US", true},
+		{"EURUSD=X", true},
+		{"", false},
+		{".SPY", false},
+		{"SPY/../../admin", false},
+		{"SPY?from=1", false},
+		{"S P Y", false},
+		{"ABCDEFGHIJKLMNOPQRSTU", false},
+	}
+
+	for _, tt := range tests {
+		err := validateTicker(tt.ticker)
+		if (err == nil) != tt.valid {
+			t.Errorf("validateTicker(%q) = %v, want valid: %v", tt.ticker, err, tt.valid)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidTicker) {
+			t.Errorf("validateTicker(%q) = %v, want it to wrap ErrInvalidTicker", tt.ticker, err)
+		}
+	}
+}
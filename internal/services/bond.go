@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// couponsPerYear treats zero-coupon bonds as paying a zero coupon once a year,
+// which keeps the discounting below uniform.
+func couponsPerYear(asset *models.Asset) int {
+	if asset.CouponFrequency <= 0 {
+		return 1
+	}
+	return asset.CouponFrequency
+}
+
+// couponAmount is the coupon paid per bond on each coupon date.
+func couponAmount(asset *models.Asset) float64 {
+	return float64(asset.FaceValue) * float64(asset.CouponRate) / 100 / float64(couponsPerYear(asset))
+}
+
+// addMonths moves t by n months, clamping the day to the end of shorter months instead of
+// overflowing into the next, so Aug 31 less 6 months is the last day of February.
+func addMonths(t time.Time, n int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(n), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	last := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(t.Day(), last)-1)
+}
+
+// couponDates returns the coupon dates in (from, to], oldest first. Dates are
+// stepped back from maturity so they never drift on short months.
+func couponDates(asset *models.Asset, from, to time.Time) []time.Time {
+	months := 12 / couponsPerYear(asset)
+
+	var dates []time.Time
+	for k := 0; ; k++ {
+		date := addMonths(asset.MaturityDate, -k*months)
+		if !date.After(from) {
+			break
+		}
+		if !date.After(to) {
+			dates = append(dates, date)
+		}
+	}
+	slices.Reverse(dates)
+	return dates
+}
+
+// couponPeriod returns the last coupon date on or before t and the next one after it.
+func couponPeriod(asset *models.Asset, t time.Time) (time.Time, time.Time) {
+	months := 12 / couponsPerYear(asset)
+	next := asset.MaturityDate
+	for k := 1; ; k++ {
+		prev := addMonths(asset.MaturityDate, -k*months)
+		if !prev.After(t) {
+			return prev, next
+		}
+		next = prev
+	}
+}
+
+// bondPrices returns the dirty price and accrued interest per bond at settlement t for an annual yield y.
+func bondPrices(asset *models.Asset, y float64, t time.Time) (float64, float64) {
+	f := float64(couponsPerYear(asset))
+	coupon := couponAmount(asset)
+
+	prev, next := couponPeriod(asset, t)
+	toNext := next.Sub(t).Hours() / next.Sub(prev).Hours()
+	remaining := len(couponDates(asset, t, asset.MaturityDate))
+
+	var dirty float64
+	for i := 0; i < remaining; i++ {
+		dirty += coupon / math.Pow(1+y/f, toNext+float64(i))
+	}
+	dirty += float64(asset.FaceValue) / math.Pow(1+y/f, toNext+float64(remaining-1))
+
+	return dirty, coupon * (1 - toNext)
+}
+
+// yieldToMaturity solves for the annual yield at which the bond's clean price at t equals price.
+func yieldToMaturity(asset *models.Asset, price float64, t time.Time) float64 {
+	low, high := -0.9, 2.0
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		dirty, accrued := bondPrices(asset, mid, t)
+		if dirty-accrued > price {
+			low = mid
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2
+}
+
+// ValueBond values a bond holding at now by carrying its purchase price forward at the
+// yield implied by it, so the price pulls to par as maturity approaches.
+func ValueBond(asset *models.Asset, holding models.Holding, now time.Time) (models.BondValuation, error) {
+	if asset.FaceValue <= 0 || asset.MaturityDate.IsZero() {
+		return models.BondValuation{}, errors.New("bond needs a face value and maturity date")
+	}
+
+	purchased := asset.CreatedAt
+	for _, lot := range holding.Lots {
+		if lot.Date.Before(purchased) {
+			purchased = lot.Date
+		}
+	}
+	purchasePrice := holding.AverageCost
+	if purchasePrice <= 0 {
+		purchasePrice = float64(asset.FaceValue)
+	}
+
+	valuation := models.BondValuation{
+		AssetID:         asset.ID,
+		ValuedAt:        now,
+		Quantity:        holding.Quantity,
+		CouponsReceived: holding.Income,
+		MaturityDate:    asset.MaturityDate,
+		Matured:         !now.Before(asset.MaturityDate),
+	}
+
+	if purchased.Before(asset.MaturityDate) {
+		valuation.YieldToMaturity = yieldToMaturity(asset, purchasePrice, purchased)
+	}
+
+	if valuation.Matured {
+		valuation.CleanPrice = float64(asset.FaceValue)
+	} else {
+		dirty, accrued := bondPrices(asset, valuation.YieldToMaturity, now)
+		valuation.CleanPrice = dirty - accrued
+		valuation.AccruedInterest = accrued
+		_, valuation.NextCouponDate = couponPeriod(asset, now)
+	}
+	valuation.DirtyPrice = valuation.CleanPrice + valuation.AccruedInterest
+
+	for _, date := range couponDates(asset, purchased, asset.MaturityDate) {
+		if couponAmount(asset) == 0 {
+			break
+		}
+		valuation.Coupons = append(valuation.Coupons, models.CouponPayment{
+			Date:   date,
+			Amount: couponAmount(asset) * holding.Quantity,
+			Paid:   !date.After(now),
+		})
+	}
+
+	return valuation, nil
+}
+
+// RecordBondCashFlows books every coupon paid since the last recorded one and, once
+// the bond has matured, the redemption of the remaining bonds at face value. They are
+// worked out from the ledger under its lock, so overlapping runs cannot book them twice.
+func (s *AssetService) RecordBondCashFlows(ctx context.Context, asset *models.Asset, now time.Time) error {
+	if asset.FaceValue <= 0 || asset.MaturityDate.IsZero() {
+		return errors.New("bond needs a face value and maturity date")
+	}
+
+	return s.recordFrom(ctx, asset, func(txs []*models.Transaction) ([]*models.Transaction, error) {
+		return bondCashFlows(asset, txs, s.lotMethod(asset), now), nil
+	})
+}
+
+// bondCashFlows returns the coupons paid after the last one in the ledger up to now and,
+// once the bond has matured, the redemption of the bonds still held.
+func bondCashFlows(asset *models.Asset, txs []*models.Transaction, method LotMethod, now time.Time) []*models.Transaction {
+	if len(txs) == 0 {
+		return nil
+	}
+
+	from := txs[0].Date
+	for _, tx := range txs {
+		if tx.Type == models.TransactionCoupon && tx.Date.After(from) {
+			from = tx.Date
+		}
+	}
+
+	until := now
+	if asset.MaturityDate.Before(until) {
+		until = asset.MaturityDate
+	}
+
+	// Coupons are paid on the quantity held on the coupon date
+	heldOn := func(date time.Time) float64 {
+		i := slices.IndexFunc(txs, func(tx *models.Transaction) bool { return tx.Date.After(date) })
+		if i < 0 {
+			i = len(txs)
+		}
+		return BuildHolding(asset, txs[:i], method).Quantity
+	}
+
+	var flows []*models.Transaction
+	for _, date := range couponDates(asset, from, until) {
+		amount := couponAmount(asset) * heldOn(date)
+		if amount <= 0 {
+			continue
+		}
+		flows = append(flows, &models.Transaction{AssetID: asset.ID, Type: models.TransactionCoupon, Amount: amount, Date: date})
+	}
+
+	if now.Before(asset.MaturityDate) {
+		return flows
+	}
+	if held := heldOn(asset.MaturityDate); held > 0 {
+		flows = append(flows, &models.Transaction{
+			AssetID:  asset.ID,
+			Type:     models.TransactionSell,
+			Quantity: held,
+			Price:    float64(asset.FaceValue),
+			Date:     asset.MaturityDate,
+			Note:     "redemption at maturity",
+		})
+	}
+	return flows
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestAddMonths(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		from   time.Time
+		months int
+		want   time.Time
+	}{
+		{date(2025, 3, 15), -6, date(2024, 9, 15)},
+		{date(2025, 8, 31), -6, date(2025, 2, 28)},
+		{date(2024, 8, 31), -6, date(2024, 2, 29)},
+		{date(2025, 5, 31), -1, date(2025, 4, 30)},
+		{date(2024, 12, 31), 2, date(2025, 2, 28)},
+	}
+
+	for _, tt := range tests {
+		if got := addMonths(tt.from, tt.months); !got.Equal(tt.want) {
+			t.Errorf("addMonths(%s, %d) = %s, want %s", tt.from.Format(time.DateOnly), tt.months,
+				got.Format(time.DateOnly), tt.want.Format(time.DateOnly))
+		}
+	}
+}
+
+func TestYieldToMaturity(t *testing.T) {
+	maturity := time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)
+	couponDate := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	midPeriod := time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)
+	bond := func(rate float32, frequency int) *models.Asset {
+		return &models.Asset{Type: "Bond", FaceValue: 1000, CouponRate: rate, CouponFrequency: frequency, MaturityDate: maturity}
+	}
+
+	tests := []struct {
+		name  string
+		asset *models.Asset
+		price float64
+		t     time.Time
+		want  float64
+	}{
+		{
+			name:  "annual coupon bought at par",
+			asset: bond(5, 1),
+			price: 1000,
+			t:     couponDate,
+			want:  0.05,
+		},
+		{
+			name:  "semiannual coupon bought at par",
+			asset: bond(4, 2),
+			price: 1000,
+			t:     couponDate,
+			want:  0.04,
+		},
+		{
+			name:  "zero coupon at a discount",
+			asset: bond(0, 0),
+			price: 1000 / math.Pow(1.05, 5),
+			t:     couponDate,
+			want:  0.05,
+		},
+		{
+			name:  "between coupons at the clean price for 6%",
+			asset: bond(4, 2),
+			price: cleanPrice(bond(4, 2), 0.06, midPeriod),
+			t:     midPeriod,
+			want:  0.06,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := yieldToMaturity(tt.asset, tt.price, tt.t); !near(got, tt.want) {
+				t.Errorf("yieldToMaturity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBondAccruedInterest(t *testing.T) {
+	asset := &models.Asset{Type: "Bond", FaceValue: 1000, CouponRate: 4, CouponFrequency: 2,
+		MaturityDate: time.Date(2030, 6, 30, 0, 0, 0, 0, time.UTC)}
+	prev := time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC)
+	next := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		t    time.Time
+		want float64
+	}{
+		{"on a coupon date", prev, 0},
+		{"halfway through the period", prev.Add(next.Sub(prev) / 2), 10},
+		{"a quarter into the period", prev.Add(next.Sub(prev) / 4), 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, accrued := bondPrices(asset, 0.04, tt.t); !near(accrued, tt.want) {
+				t.Errorf("accrued interest = %v, want %v", accrued, tt.want)
+			}
+		})
+	}
+}
+
+func TestValueBond(t *testing.T) {
+	asset := &models.Asset{ID: 1, Type: "Bond", FaceValue: 1000, CouponRate: 4, CouponFrequency: 2,
+		MaturityDate: time.Date(2027, 6, 30, 0, 0, 0, 0, time.UTC),
+		CreatedAt:    time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)}
+	holding := BuildHolding(asset, []*models.Transaction{
+		{ID: 1, Type: models.TransactionBuy, Quantity: 2, Price: 1000, Date: asset.CreatedAt},
+	}, LotFIFO)
+
+	valuation, err := ValueBond(asset, holding, time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("ValueBond() error = %v", err)
+	}
+	if !near(valuation.YieldToMaturity, 0.04) || !near(valuation.CleanPrice, 1000) || valuation.AccruedInterest != 0 {
+		t.Errorf("ValueBond() = %v clean at %v yield with %v accrued, want par at 0.04 with none",
+			valuation.CleanPrice, valuation.YieldToMaturity, valuation.AccruedInterest)
+	}
+	if len(valuation.Coupons) != 4 || !near(valuation.Coupons[0].Amount, 40) {
+		t.Fatalf("ValueBond() scheduled %+v, want 4 coupons of 40", valuation.Coupons)
+	}
+	for i, coupon := range valuation.Coupons {
+		if wantPaid := i < 2; coupon.Paid != wantPaid {
+			t.Errorf("coupon on %s paid = %v, want %v", coupon.Date.Format(time.DateOnly), coupon.Paid, wantPaid)
+		}
+	}
+
+	matured, err := ValueBond(asset, holding, asset.MaturityDate)
+	if err != nil {
+		t.Fatalf("ValueBond() error = %v", err)
+	}
+	if !matured.Matured || matured.CleanPrice != 1000 || matured.AccruedInterest != 0 {
+		t.Errorf("ValueBond() at maturity = %+v, want matured at par", matured)
+	}
+}
+
+func cleanPrice(asset *models.Asset, y float64, t time.Time) float64 {
+	dirty, accrued := bondPrices(asset, y, t)
+	return dirty - accrued
+}
+
+func TestBondCashFlows(t *testing.T) {
+	asset := &models.Asset{ID: 1, Type: "Bond", FaceValue: 1000, CouponRate: 4, CouponFrequency: 2,
+		MaturityDate: time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)}
+	bought := &models.Transaction{ID: 1, Type: models.TransactionBuy, Quantity: 10, Price: 990,
+		Date: time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)}
+	after := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	flows := bondCashFlows(asset, []*models.Transaction{bought}, LotFIFO, after)
+	if len(flows) != 3 {
+		t.Fatalf("bondCashFlows() = %d transactions, want two coupons and the redemption", len(flows))
+	}
+	for i, date := range []time.Time{time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC), asset.MaturityDate} {
+		if flows[i].Type != models.TransactionCoupon || !flows[i].Date.Equal(date) || !near(flows[i].Amount, 200) {
+			t.Errorf("flow %d = %s of %v on %s, want a coupon of 200 on %s", i, flows[i].Type, flows[i].Amount,
+				flows[i].Date.Format(time.DateOnly), date.Format(time.DateOnly))
+		}
+	}
+	if redemption := flows[2]; redemption.Type != models.TransactionSell || redemption.Quantity != 10 || redemption.Price != 1000 {
+		t.Errorf("redemption = %+v, want 10 sold at 1000", redemption)
+	}
+
+	// Run again over the ledger the first run wrote, nothing is left to book
+	ledger := append([]*models.Transaction{bought}, flows...)
+	if again := bondCashFlows(asset, ledger, LotFIFO, after); len(again) != 0 {
+		t.Errorf("bondCashFlows() booked %d transactions twice", len(again))
+	}
+}
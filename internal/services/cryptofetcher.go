@@ -0,0 +1,107 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// CryptoFetcher fetches coin prices from CoinGecko, where an asset's ticker is the CoinGecko coin id (e.g. "bitcoin").
+// Coin ids are lowercase; QuoteSymbol lowercases the tickers of crypto assets.
+type CryptoFetcher struct{}
+
+func NewCryptoFetcher() *CryptoFetcher {
+	return &CryptoFetcher{}
+}
+
+func (c *CryptoFetcher) Name() string {
+	return "coingecko"
+}
+
+func (c *CryptoFetcher) Quote(ctx context.Context, coin, currency string) (models.Quote, error) {
+	vsCurrency := strings.ToLower(currency)
+	reqUrl := fmt.Sprintf("https://api.coingecko.com/api/v3/simple/price?ids=%s&vs_currencies=%s",
+		url.QueryEscape(coin), url.QueryEscape(vsCurrency))
+
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return models.Quote{}, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return models.Quote{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var cryptoData map[string]map[string]float64
+	if err := json.NewDecoder(resp.Body).Decode(&cryptoData); err != nil {
+		return models.Quote{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	price, ok := cryptoData[coin][vsCurrency]
+	if !ok {
+		return models.Quote{}, fmt.Errorf("no %s price found for %s", currency, coin)
+	}
+
+	return models.Quote{
+		Symbol:    coin,
+		Price:     price,
+		Currency:  currency,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// History fetches daily coin prices between from and to, keeping the last price of each day.
+func (c *CryptoFetcher) History(ctx context.Context, coin, currency string, from, to time.Time) ([]models.Quote, error) {
+	reqUrl := fmt.Sprintf("https://api.coingecko.com/api/v3/coins/%s/market_chart/range?vs_currency=%s&from=%d&to=%d",
+		url.PathEscape(coin), url.QueryEscape(strings.ToLower(currency)), from.Unix(), to.AddDate(0, 0, 1).Unix())
+
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var chart struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&chart); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	var quotes []models.Quote
+	for _, point := range chart.Prices {
+		date := day(time.UnixMilli(int64(point[0])))
+		quote := models.Quote{Symbol: coin, Price: point[1], Currency: currency, Timestamp: date}
+		if n := len(quotes); n > 0 && quotes[n-1].Timestamp.Equal(date) {
+			quotes[n-1] = quote
+			continue
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/pkg/cache"
+	"github.com/jagac/pfinance/pkg/worker"
+)
+
+// CurrencyConverter converts amounts into the portfolio's base currency using
+// the rates cached by the fxRates worker task, fetching them itself when the cache is cold.
+type CurrencyConverter struct {
+	Base    string
+	cache   *cache.Cache[string, worker.TaskResult]
+	fetcher RateFetcher
+}
+
+func NewCurrencyConverter(base string, cache *cache.Cache[string, worker.TaskResult], fetcher RateFetcher) *CurrencyConverter {
+	return &CurrencyConverter{Base: base, cache: cache, fetcher: fetcher}
+}
+
+// Rates returns the current exchange rates against the base currency.
+func (c *CurrencyConverter) Rates() (FXRates, error) {
+	if cached, ok := c.cache.Get("fxRates"); ok {
+		if rates, ok := cached.Value.(FXRates); ok && rates.Base == c.Base {
+			return rates, nil
+		}
+	}
+
+	rates, err := c.fetcher.FetchRates(c.Base)
+	if err != nil {
+		return FXRates{}, fmt.Errorf("exchange rates unavailable: %w", err)
+	}
+	c.cache.Set("fxRates", worker.TaskResult{Value: rates}, 29*time.Minute)
+	return rates, nil
+}
+
+// Convert converts amount between two currencies, treating an empty currency as the base currency.
+func (c *CurrencyConverter) Convert(amount float64, from, to string) (float64, error) {
+	from, to = c.orBase(from), c.orBase(to)
+	if from == to {
+		return amount, nil
+	}
+
+	rates, err := c.Rates()
+	if err != nil {
+		return 0, err
+	}
+	return rates.Convert(amount, from, to)
+}
+
+// Valuate marks a holding at a quote and reports the result both in the
+// asset's own currency and in the base currency.
+func (c *CurrencyConverter) Valuate(asset *models.Asset, holding models.Holding, quote models.Quote) (models.Valuation, error) {
+	currency := c.orBase(asset.Currency)
+
+	nativePrice, err := c.Convert(quote.Price, quote.Currency, currency)
+	if err != nil {
+		return models.Valuation{}, err
+	}
+
+	valuation, err := c.valuation(asset, holding, nativePrice, PnL(holding, nativePrice))
+	if err != nil {
+		return models.Valuation{}, err
+	}
+	valuation.PriceSource = quote.Source
+	valuation.PricedAt = quote.Timestamp
+	return valuation, nil
+}
+
+// valuation fills in the base-currency side of a valuation from its native figures.
+// Realized gains are translated at today's rate like everything else.
+func (c *CurrencyConverter) valuation(asset *models.Asset, holding models.Holding, price float64, pnl models.PnL) (models.Valuation, error) {
+	currency := c.orBase(asset.Currency)
+	rate, err := c.Convert(1, currency, c.Base)
+	if err != nil {
+		return models.Valuation{}, err
+	}
+
+	marketValue := holding.CostBasis + pnl.Unrealized
+	return models.Valuation{
+		AssetID:         asset.ID,
+		Currency:        currency,
+		Quantity:        holding.Quantity,
+		Price:           price,
+		MarketValue:     marketValue,
+		CostBasis:       holding.CostBasis,
+		PnL:             pnl,
+		BaseCurrency:    c.Base,
+		BaseMarketValue: marketValue * rate,
+		BaseCostBasis:   holding.CostBasis * rate,
+		BasePnL:         models.NewPnL(asset.ID, pnl.Realized*rate, pnl.Unrealized*rate),
+	}, nil
+}
+
+func (c *CurrencyConverter) orBase(currency string) string {
+	if currency == "" {
+		return c.Base
+	}
+	return currency
+}
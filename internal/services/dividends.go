@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// DividendService books the dividends of stock holdings and reports dividend income.
+// Dividends can also be entered by hand as dividend transactions.
+type DividendService struct {
+	assetRepo *repositories.AssetRepository
+	assets    *AssetService
+	converter *CurrencyConverter
+	prices    *PriceRegistry
+}
+
+func NewDividendService(assetRepo *repositories.AssetRepository,
+	assets *AssetService,
+	converter *CurrencyConverter,
+	prices *PriceRegistry) *DividendService {
+	return &DividendService{assetRepo: assetRepo, assets: assets, converter: converter, prices: prices}
+}
+
+// FetchDividends asks the price providers for the dividends of every stock and books those
+// not yet in its ledger, on the shares held before the ex-date. A stock whose dividends
+// cannot be fetched or booked does not keep the others from being booked.
+func (s *DividendService) FetchDividends(ctx context.Context, now time.Time) error {
+	assets, err := s.assetRepo.GetAssetsByType(ctx, "Stock")
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, asset := range assets {
+		if err := s.recordDividends(ctx, asset, now); err != nil {
+			errs = append(errs, fmt.Errorf("dividends of %s: %w", asset.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *DividendService) recordDividends(ctx context.Context, asset *models.Asset, now time.Time) error {
+	txs, err := s.assets.ledger(ctx, asset)
+	if err != nil || len(txs) == 0 {
+		return err
+	}
+
+	dividends, err := s.prices.Dividends(ctx, asset.Type, QuoteSymbol(asset), txs[0].Date, now)
+	if err != nil {
+		return err
+	}
+	rates := make(map[string]float64)
+	for _, dividend := range dividends {
+		if _, ok := rates[dividend.Currency]; ok {
+			continue
+		}
+		if rates[dividend.Currency], err = s.converter.Convert(1, dividend.Currency, asset.Currency); err != nil {
+			return err
+		}
+	}
+
+	return s.assets.recordFrom(ctx, asset, func(txs []*models.Transaction) ([]*models.Transaction, error) {
+		return dividendTransactions(asset, txs, s.assets.lotMethod(asset), dividends, rates), nil
+	})
+}
+
+// dividendTransactions returns the dividends to book on the shares held before each ex-date,
+// skipping ex-dates that already have a dividend in the ledger, whether fetched or entered
+// by hand. rates converts each dividend's currency to the asset's.
+func dividendTransactions(asset *models.Asset, txs []*models.Transaction, method LotMethod,
+	dividends []models.Dividend, rates map[string]float64) []*models.Transaction {
+	var booked []*models.Transaction
+	for _, dividend := range dividends {
+		exDate := day(dividend.ExDate)
+		if slices.ContainsFunc(txs, func(tx *models.Transaction) bool {
+			return tx.Type == models.TransactionDividend && day(tx.Date).Equal(exDate)
+		}) {
+			continue
+		}
+		held := heldBefore(asset, txs, method, exDate)
+		if held <= 0 {
+			continue
+		}
+
+		booked = append(booked, &models.Transaction{
+			AssetID: asset.ID,
+			Type:    models.TransactionDividend,
+			Amount:  dividend.Amount * held * rates[dividend.Currency],
+			Date:    dividend.ExDate,
+			Note:    fmt.Sprintf("%g %s per share", dividend.Amount, dividend.Currency),
+		})
+	}
+	return booked
+}
+
+// heldBefore returns the shares held at the end of the day before exDate, counting every
+// transaction made at any time of that day.
+func heldBefore(asset *models.Asset, txs []*models.Transaction, method LotMethod, exDate time.Time) float64 {
+	i := slices.IndexFunc(txs, func(tx *models.Transaction) bool { return !tx.Date.Before(day(exDate)) })
+	if i < 0 {
+		i = len(txs)
+	}
+	if i == 0 {
+		return 0
+	}
+	return BuildHolding(asset, txs[:i], method).Quantity
+}
+
+// Report sums the dividends received between from and to per month and per asset in the
+// base currency, converted at today's rates.
+func (s *DividendService) Report(ctx context.Context, from, to time.Time) (models.DividendReport, error) {
+	assets, err := s.assetRepo.GetAllAssets(ctx)
+	if err != nil {
+		return models.DividendReport{}, err
+	}
+	ledgers, err := s.assets.TxRepo.GetAllTransactions(ctx)
+	if err != nil {
+		return models.DividendReport{}, err
+	}
+
+	report := models.DividendReport{
+		From:         from,
+		To:           to,
+		BaseCurrency: s.converter.Base,
+		ByMonth:      []models.DividendMonth{},
+		ByAsset:      []models.AssetDividends{},
+	}
+	byMonth := make(map[time.Time]float64)
+	yearStart := to.AddDate(-1, 0, 0)
+
+	for _, asset := range assets {
+		rate, err := s.converter.Convert(1, asset.Currency, s.converter.Base)
+		if err != nil {
+			return models.DividendReport{}, err
+		}
+
+		line := models.AssetDividends{AssetID: asset.ID, Name: asset.Name}
+		var held []*models.Transaction
+		for _, tx := range ledgers[asset.ID] {
+			if tx.Date.After(to) {
+				break
+			}
+			held = append(held, tx)
+			if tx.Type != models.TransactionDividend {
+				continue
+			}
+
+			amount := tx.Amount * rate
+			if !tx.Date.Before(from) {
+				line.Amount += amount
+				byMonth[periodStarts["monthly"](tx.Date)] += amount
+			}
+			if tx.Date.After(yearStart) {
+				line.TrailingYear += amount
+			}
+		}
+		if line.Amount == 0 && line.TrailingYear == 0 {
+			continue
+		}
+
+		line.CostBasis = BuildHolding(asset, held, s.assets.lotMethod(asset)).CostBasis * rate
+		if line.CostBasis > 0 {
+			line.YieldOnCost = line.TrailingYear / line.CostBasis * 100
+		}
+		report.Total += line.Amount
+		report.ByAsset = append(report.ByAsset, line)
+	}
+
+	for month, amount := range byMonth {
+		report.ByMonth = append(report.ByMonth, models.DividendMonth{Month: month, Amount: amount})
+	}
+	slices.SortFunc(report.ByMonth, func(a, b models.DividendMonth) int { return a.Month.Compare(b.Month) })
+
+	return report, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestHeldBefore(t *testing.T) {
+	at := func(d, hour int) time.Time { return time.Date(2024, 1, d, hour, 0, 0, 0, time.UTC) }
+	trade := func(id int, typ string, quantity float64, date time.Time) *models.Transaction {
+		return &models.Transaction{ID: id, Type: typ, Quantity: quantity, Price: 100, Date: date}
+	}
+	exDate := at(10, 0)
+
+	tests := []struct {
+		name string
+		txs  []*models.Transaction
+		want float64
+	}{
+		{
+			name: "bought days before",
+			txs:  []*models.Transaction{trade(1, models.TransactionBuy, 10, at(2, 0))},
+			want: 10,
+		},
+		{
+			name: "bought during the day before",
+			txs:  []*models.Transaction{trade(1, models.TransactionBuy, 10, at(2, 0)), trade(2, models.TransactionBuy, 5, at(9, 15))},
+			want: 15,
+		},
+		{
+			name: "bought on the ex-date",
+			txs:  []*models.Transaction{trade(1, models.TransactionBuy, 10, at(2, 0)), trade(2, models.TransactionBuy, 5, at(10, 9))},
+			want: 10,
+		},
+		{
+			name: "sold the day before",
+			txs:  []*models.Transaction{trade(1, models.TransactionBuy, 10, at(2, 0)), trade(2, models.TransactionSell, 4, at(9, 20))},
+			want: 6,
+		},
+		{
+			name: "first bought on the ex-date",
+			txs:  []*models.Transaction{trade(1, models.TransactionBuy, 10, at(10, 9))},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := &models.Asset{ID: 1, Type: "Stock", Amount: 99, Price: 1}
+			if got := heldBefore(asset, tt.txs, LotFIFO, exDate); got != tt.want {
+				t.Errorf("heldBefore() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDividendTransactions(t *testing.T) {
+	asset := &models.Asset{ID: 1, Type: "Stock", Currency: "EUR"}
+	bought := &models.Transaction{ID: 1, Type: models.TransactionBuy, Quantity: 10, Price: 100,
+		Date: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	manual := &models.Transaction{ID: 2, Type: models.TransactionDividend, Amount: 7,
+		Date: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)}
+	dividends := []models.Dividend{
+		{ExDate: time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC), Amount: 0.5, Currency: "USD"},
+		{ExDate: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), Amount: 0.7, Currency: "USD"},
+		{ExDate: time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC), Amount: 0.4, Currency: "USD"},
+	}
+
+	booked := dividendTransactions(asset, []*models.Transaction{bought, manual}, LotFIFO, dividends,
+		map[string]float64{"USD": 0.9})
+
+	// The June dividend was entered by hand, and the shares were bought after the 2023 one
+	if len(booked) != 1 {
+		t.Fatalf("dividendTransactions() booked %d dividends, want 1", len(booked))
+	}
+	if got := booked[0]; !got.Date.Equal(dividends[0].ExDate) || !near(got.Amount, 0.5*10*0.9) {
+		t.Errorf("booked %v on %s, want %v on %s", got.Amount, got.Date.Format(time.DateOnly),
+			0.5*10*0.9, dividends[0].ExDate.Format(time.DateOnly))
+	}
+}
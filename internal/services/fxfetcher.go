@@ -0,0 +1,87 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RateFetcher fetches exchange rates quoted against a base currency.
+type RateFetcher interface {
+	FetchRates(base string) (FXRates, error)
+}
+
+// FXRates holds how many units of each currency one unit of Base buys.
+type FXRates struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	Timestamp time.Time          `json:"timestamp"`
+}
+
+// Convert converts amount from one currency to another through the base currency.
+func (f FXRates) Convert(amount float64, from, to string) (float64, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	fromRate, err := f.rate(from)
+	if err != nil {
+		return 0, err
+	}
+	toRate, err := f.rate(to)
+	if err != nil {
+		return 0, err
+	}
+
+	return amount / fromRate * toRate, nil
+}
+
+func (f FXRates) rate(currency string) (float64, error) {
+	if currency == f.Base {
+		return 1, nil
+	}
+	rate, ok := f.Rates[currency]
+	if !ok || rate == 0 {
+		return 0, fmt.Errorf("no %s exchange rate for %s", f.Base, currency)
+	}
+	return rate, nil
+}
+
+// FrankfurterFetcher fetches the ECB reference rates published by frankfurter.app.
+type FrankfurterFetcher struct{}
+
+func NewFrankfurterFetcher() *FrankfurterFetcher {
+	return &FrankfurterFetcher{}
+}
+
+func (f *FrankfurterFetcher) FetchRates(base string) (FXRates, error) {
+	reqUrl := fmt.Sprintf("https://api.frankfurter.app/latest?from=%s", base)
+
+	client := &http.Client{}
+
+	req, err := http.NewRequest("GET", reqUrl, nil)
+	if err != nil {
+		return FXRates{}, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return FXRates{}, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return FXRates{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var fxData struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&fxData); err != nil {
+		return FXRates{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return FXRates{Base: fxData.Base, Rates: fxData.Rates, Timestamp: time.Now()}, nil
+}
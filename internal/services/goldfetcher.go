@@ -1,41 +1,48 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/jagac/pfinance/internal/models"
 )
 
+// gramsPerTroyOunce converts gold prices quoted per ounce into the per gram prices gold assets are held in.
+const gramsPerTroyOunce = 31.1035
+
+// GoldFetcher fetches the gold price per gram from goldprice.org.
 type GoldFetcher struct{}
+
 func NewGoldFetcher() *GoldFetcher {
 	return &GoldFetcher{}
 }
 
-type GoldResponse struct {
-	Price     float64   `json:"xauPrice"`
-	Timestamp time.Time `json:"timestamp"`
+func (g *GoldFetcher) Name() string {
+	return "goldprice"
 }
 
-func (g *GoldFetcher) FetchPrice(currency string) (GoldResponse, error) {
+func (g *GoldFetcher) Quote(ctx context.Context, _, currency string) (models.Quote, error) {
 	// Format the URL for the specific currency (e.g., USD)
 	reqUrl := fmt.Sprintf("https://data-asg.goldprice.org/dbXRates/%s", currency)
 
 	client := &http.Client{}
 
 	// Create the GET request
-	req, err := http.NewRequest("GET", reqUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
 	if err != nil {
-		return GoldResponse{}, fmt.Errorf("failed to create request: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to create request: %v", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return GoldResponse{}, fmt.Errorf("failed to send request: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return GoldResponse{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return models.Quote{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var goldData struct {
@@ -45,18 +52,18 @@ func (g *GoldFetcher) FetchPrice(currency string) (GoldResponse, error) {
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&goldData); err != nil {
-		return GoldResponse{}, fmt.Errorf("failed to decode response: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to decode response: %v", err)
 	}
-	pricePerOunce := goldData.Items[0].XAUPrice
-	pricePerGram := pricePerOunce / 31.1035
 
 	if len(goldData.Items) == 0 {
-		return GoldResponse{}, fmt.Errorf("no price data found for the requested currency")
-	}
-	goldResponse := GoldResponse{
-		Price:     pricePerGram,
-		Timestamp: time.Now(),
+		return models.Quote{}, fmt.Errorf("no price data found for the requested currency")
 	}
+	pricePerOunce := goldData.Items[0].XAUPrice
 
-	return goldResponse, nil
+	return models.Quote{
+		Symbol:    GoldSymbol,
+		Price:     pricePerOunce / gramsPerTroyOunce,
+		Currency:  currency,
+		Timestamp: time.Now(),
+	}, nil
 }
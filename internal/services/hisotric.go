@@ -2,100 +2,228 @@ package services
 
 import (
 	"context"
-	"math"
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/jagac/pfinance/internal/models"
 	"github.com/jagac/pfinance/internal/repositories"
 )
 
+// ErrUnknownInterval is returned for a time series interval that is not supported.
+var ErrUnknownInterval = errors.New("unknown interval")
+
 type HistoricReturns struct {
 	assetRepo    *repositories.AssetRepository
+	assets       *AssetService
 	historicRepo *repositories.AssetReturnHistoryRepository
-	goldFetcher  *GoldFetcher
-	stockFetcher *StockFetcher
+	converter    *CurrencyConverter
+	prices       *PriceRegistry
+	priceRepo    *repositories.PriceRepository
+	snapshotRepo *repositories.SnapshotRepository
 }
 
 func NewHistoricReturns(assetRepo *repositories.AssetRepository,
+	assets *AssetService,
 	historicRepo *repositories.AssetReturnHistoryRepository,
-	goldFetcher *GoldFetcher,
-	stockFetcher *StockFetcher) *HistoricReturns {
-	return &HistoricReturns{assetRepo: assetRepo, historicRepo: historicRepo, goldFetcher: goldFetcher, stockFetcher: stockFetcher}
+	converter *CurrencyConverter,
+	prices *PriceRegistry,
+	priceRepo *repositories.PriceRepository,
+	snapshotRepo *repositories.SnapshotRepository) *HistoricReturns {
+	return &HistoricReturns{assetRepo: assetRepo, assets: assets, historicRepo: historicRepo, converter: converter,
+		prices: prices, priceRepo: priceRepo, snapshotRepo: snapshotRepo}
 }
 
+// Calc records today's P&L of every asset in asset_returns and takes a snapshot of every
+// asset, of every portfolio and of each owner's total across their portfolios. An asset that
+// cannot be valued does not stop the others; the totals it belongs to are left out, so that
+// catching up the day later fills them in, and its error is returned with the rest.
 func (r *HistoricReturns) Calc(ctx context.Context) error {
 	assets, err := r.assetRepo.GetAllAssets(ctx)
 	if err != nil {
 		return err
 	}
 
+	now := time.Now()
+	totals := newSnapshotTotals(r.converter.Base)
+
+	var errs []error
 	for _, asset := range assets {
-		if asset.Type == "Stock" {
-
-			stockPrice, err := r.stockFetcher.FetchPrice(asset.Ticker)
-			if err != nil {
-				return err
-			}
-
-			currentPrice32 := float32(stockPrice.Price)
-			pnl := (currentPrice32 - asset.Price) * asset.Amount
-			err = r.historicRepo.InsertAssetReturn(ctx, asset.ID, pnl, &stockPrice.Timestamp)
-			if err != nil {
-				return err
-			}
+		if err := r.calcAsset(ctx, asset, now, totals); err != nil {
+			totals.fail(asset, now)
+			errs = append(errs, fmt.Errorf("returns of %s: %w", asset.Name, err))
 		}
+	}
 
-		if asset.Type == "Gold" {
-
-			currentGoldPrice, err := r.stockFetcher.FetchPrice(asset.Ticker)
-			if err != nil {
-				return err
-			}
-			currentPrice32 := float32(currentGoldPrice.Price)
-			pnl := (currentPrice32 - asset.Price) * asset.Amount
-			err = r.historicRepo.InsertAssetReturn(ctx, asset.ID, pnl, &currentGoldPrice.Timestamp)
-			if err != nil {
-				return err
-			}
+	for _, total := range totals.snapshots() {
+		if err := r.snapshotRepo.UpsertSnapshot(ctx, total); err != nil {
+			errs = append(errs, err)
 		}
+	}
+	return errors.Join(errs...)
+}
 
-		if asset.Type == "Savings" {
-			principal := float64(asset.Amount)
-			interestRate := float64(asset.InterestRate) / 100
-			startDate := asset.InterestStart
-			now := time.Now()
-
-			if now.Before(startDate) {
-				continue
-			}
-
-			monthsElapsed := float64(now.Year()-startDate.Year())*12 + float64(now.Month()-startDate.Month())
-
-			if monthsElapsed <= 0 {
-				continue
-			}
-
-			var compoundingPeriods int
-			switch asset.CompoundingFrequency {
-			case "daily":
-				compoundingPeriods = 365
-			case "quarterly":
-				compoundingPeriods = 4
-			case "annually":
-				compoundingPeriods = 1
-			default:
-				compoundingPeriods = 12
-			}
-
-			yearsElapsed := monthsElapsed / 12
-			finalAmount := principal * math.Pow(1+interestRate/float64(compoundingPeriods), float64(compoundingPeriods)*yearsElapsed)
-			pl := finalAmount - principal
-			pl32 := float32(pl)
-
-			err = r.historicRepo.InsertAssetReturn(ctx, asset.ID, pl32, &now)
-			if err != nil {
-				return err
-			}
-		}
+func (r *HistoricReturns) calcAsset(ctx context.Context, asset *models.Asset, now time.Time, totals *snapshotTotals) error {
+	valuation, date, ok, err := r.value(ctx, asset, now)
+	if err != nil || !ok {
+		return err
 	}
+
+	if err := r.historicRepo.UpsertAssetReturn(ctx, valuation.BasePnL, &date); err != nil {
+		return err
+	}
+	if err := r.snapshotRepo.UpsertSnapshot(ctx, snapshotOf(asset, valuation, now)); err != nil {
+		return err
+	}
+	totals.add(asset, valuation, now)
 	return nil
 }
+
+// snapshotTotals adds up the valuations of assets on a day into the total of each portfolio
+// and of each owner across their portfolios.
+type snapshotTotals struct {
+	base   string
+	totals map[totalKey]*models.Snapshot
+	failed map[totalKey]bool
+}
+
+// totalKey identifies a total by day, owner and portfolio, with 0 for assets created before
+// there were accounts and for the total across an owner's portfolios.
+type totalKey struct {
+	date             time.Time
+	owner, portfolio int
+}
+
+func newSnapshotTotals(base string) *snapshotTotals {
+	return &snapshotTotals{base: base, totals: make(map[totalKey]*models.Snapshot), failed: make(map[totalKey]bool)}
+}
+
+// keys returns the totals an asset counts towards on a day, with their portfolio IDs.
+func (t *snapshotTotals) keys(asset *models.Asset, date time.Time) map[totalKey]*int {
+	owner := 0
+	if asset.OwnerID != nil {
+		owner = *asset.OwnerID
+	}
+	keys := map[totalKey]*int{{date: day(date), owner: owner}: nil}
+	if asset.PortfolioID != nil {
+		keys[totalKey{date: day(date), owner: owner, portfolio: *asset.PortfolioID}] = asset.PortfolioID
+	}
+	return keys
+}
+
+func (t *snapshotTotals) add(asset *models.Asset, v models.Valuation, date time.Time) {
+	for key, portfolioID := range t.keys(asset, date) {
+		total, ok := t.totals[key]
+		if !ok {
+			total = &models.Snapshot{Date: date, OwnerID: asset.OwnerID, PortfolioID: portfolioID,
+				Currency: t.base, BaseCurrency: t.base}
+			t.totals[key] = total
+		}
+		total.BaseMarketValue += v.BaseMarketValue
+		total.BaseCostBasis += v.BaseCostBasis
+		total.BasePnL.Realized += v.BasePnL.Realized
+		total.BasePnL.Unrealized += v.BasePnL.Unrealized
+	}
+}
+
+// fail leaves out the totals an asset that could not be valued on a day counts towards.
+func (t *snapshotTotals) fail(asset *models.Asset, date time.Time) {
+	for key := range t.keys(asset, date) {
+		t.failed[key] = true
+	}
+}
+
+// snapshots returns the complete totals.
+func (t *snapshotTotals) snapshots() []models.Snapshot {
+	var snapshots []models.Snapshot
+	for key, total := range t.totals {
+		if t.failed[key] {
+			continue
+		}
+		total.MarketValue, total.CostBasis = total.BaseMarketValue, total.BaseCostBasis
+		total.PnL = models.NewPnL(0, total.BasePnL.Realized, total.BasePnL.Unrealized)
+		total.BasePnL = total.PnL
+		snapshots = append(snapshots, *total)
+	}
+	return snapshots
+}
+
+// value marks an asset to market as of now and returns the day its P&L is recorded under,
+// which for priced assets is the time of the quote. It reports false for an asset that
+// cannot be valued yet, such as a savings account before interest starts.
+func (r *HistoricReturns) value(ctx context.Context, asset *models.Asset, now time.Time) (models.Valuation, time.Time, bool, error) {
+	holding, err := r.assets.GetHolding(ctx, asset)
+	if err != nil {
+		return models.Valuation{}, now, false, err
+	}
+
+	switch asset.Type {
+	case "Stock", "Gold", "Crypto":
+		quote, err := r.prices.Quote(ctx, asset.Type, QuoteSymbol(asset), r.converter.Base)
+		if err != nil {
+			return models.Valuation{}, now, false, err
+		}
+		if err := r.priceRepo.InsertQuote(ctx, asset.Type, quote); err != nil {
+			return models.Valuation{}, now, false, err
+		}
+
+		valuation, err := r.converter.Valuate(asset, holding, quote)
+		return valuation, quote.Timestamp, err == nil, err
+
+	case "Bond":
+		if err := r.assets.RecordBondCashFlows(ctx, asset, now); err != nil {
+			return models.Valuation{}, now, false, err
+		}
+		holding, err = r.assets.GetHolding(ctx, asset)
+		if err != nil {
+			return models.Valuation{}, now, false, err
+		}
+
+		value, err := ValueBond(asset, holding, now)
+		if err != nil {
+			return models.Valuation{}, now, false, err
+		}
+		valuation, err := r.converter.Valuate(asset, holding, value.Quote(asset.Currency))
+		return valuation, now, err == nil, err
+
+	case "Savings":
+		interest, ok, err := r.assets.AccruedInterest(ctx, asset, now)
+		if err != nil || !ok {
+			return models.Valuation{}, now, false, err
+		}
+
+		valuation, err := r.converter.valuation(asset, holding, 1, models.NewPnL(asset.ID, 0, interest))
+		return valuation, now, err == nil, err
+	}
+
+	return models.Valuation{}, now, false, nil
+}
+
+// snapshotOf turns the valuation of an asset into its snapshot for a day.
+func snapshotOf(asset *models.Asset, v models.Valuation, date time.Time) models.Snapshot {
+	return models.Snapshot{
+		Date:            date,
+		AssetID:         &asset.ID,
+		OwnerID:         asset.OwnerID,
+		Currency:        v.Currency,
+		Quantity:        v.Quantity,
+		Price:           v.Price,
+		MarketValue:     v.MarketValue,
+		CostBasis:       v.CostBasis,
+		PnL:             v.PnL,
+		BaseCurrency:    v.BaseCurrency,
+		BaseMarketValue: v.BaseMarketValue,
+		BaseCostBasis:   v.BaseCostBasis,
+		BasePnL:         v.BasePnL,
+	}
+}
+
+// Snapshots returns the snapshots of an asset, or of the portfolio total when assetID is nil,
+// between from and to, keeping the last one of every day, week or month.
+func (r *HistoricReturns) Snapshots(ctx context.Context, assetID *int, from, to time.Time, interval string) ([]models.Snapshot, error) {
+	unit, ok := map[string]string{"": "day", "daily": "day", "weekly": "week", "monthly": "month"}[interval]
+	if !ok {
+		return nil, fmt.Errorf("%w %q, expected daily, weekly or monthly", ErrUnknownInterval, interval)
+	}
+	return r.snapshotRepo.GetSnapshots(ctx, assetID, from, to, unit)
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// openingTransactions stands in for the ledger of assets created before
+// transactions existed, using the single price and amount stored on the asset.
+func openingTransactions(asset *models.Asset) []*models.Transaction {
+	if asset.Amount <= 0 {
+		return nil
+	}
+
+	if asset.Type == "Savings" {
+		return []*models.Transaction{{
+			AssetID: asset.ID,
+			Type:    models.TransactionDeposit,
+			Amount:  float64(asset.Amount),
+			Date:    asset.InterestStart,
+		}}
+	}
+
+	price := asset.Price
+	if asset.Type == "Bond" && asset.PurchasePrice > 0 {
+		price = asset.PurchasePrice
+	}
+
+	return []*models.Transaction{{
+		AssetID:  asset.ID,
+		Type:     models.TransactionBuy,
+		Quantity: float64(asset.Amount),
+		Price:    float64(price),
+		Date:     asset.CreatedAt,
+	}}
+}
+
+// PnL splits the profit or loss of a holding marked at the given price into
+// what was already realized by sells, fees and income and what is still on paper.
+func PnL(holding models.Holding, price float64) models.PnL {
+	return models.NewPnL(holding.AssetID, holding.Realized-holding.Fees+holding.Income, holding.Quantity*price-holding.CostBasis)
+}
+
+// netFlow is the money a transaction moved into a position, negative when money came out.
+// It mirrors what PnL books as realized, so a position is worth its P&L plus its net flows.
+func netFlow(tx *models.Transaction) float64 {
+	switch tx.Type {
+	case models.TransactionBuy:
+		return tx.Quantity*tx.Price + tx.Fee
+	case models.TransactionSell:
+		return -(tx.Quantity*tx.Price - tx.Fee)
+	case models.TransactionDeposit, models.TransactionFee:
+		return tx.Amount
+	case models.TransactionWithdrawal, models.TransactionCoupon, models.TransactionDividend:
+		return -tx.Amount
+	}
+	return 0
+}
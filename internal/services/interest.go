@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// AccruedInterest returns the interest a savings asset has earned up to now on its dated
+// deposits and withdrawals at the rates in force at the time. It reports false before
+// interest starts.
+func (s *AssetService) AccruedInterest(ctx context.Context, asset *models.Asset, now time.Time) (float64, bool, error) {
+	txs, err := s.TxRepo.GetTransactionsByAsset(ctx, asset.ID)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(txs) == 0 {
+		txs = openingTransactions(asset)
+	}
+	rates, err := s.RateRepo.GetRatesByAsset(ctx, asset.ID)
+	if err != nil {
+		return 0, false, err
+	}
+
+	interest, ok := accruedInterest(asset, txs, rates, now)
+	return interest, ok, nil
+}
+
+func (s *AssetService) AddInterestRate(ctx context.Context, rate *models.InterestRate) error {
+	asset, err := s.Repo.GetAssetByID(ctx, rate.AssetID)
+	if err != nil {
+		return err
+	}
+	if asset.Type != "Savings" {
+		return errors.New("only savings assets have interest rates")
+	}
+	if rate.Rate < 0 || rate.EffectiveFrom.IsZero() {
+		return errors.New("a rate change needs a non-negative rate and an effective date")
+	}
+	return s.RateRepo.AddRate(ctx, rate)
+}
+
+func (s *AssetService) GetInterestRates(ctx context.Context, assetID int) ([]models.InterestRate, error) {
+	return s.RateRepo.GetRatesByAsset(ctx, assetID)
+}
+
+func (s *AssetService) DeleteInterestRate(ctx context.Context, assetID, id int) error {
+	return s.RateRepo.DeleteRate(ctx, assetID, id)
+}
+
+// accruedInterest accrues interest day by day from the asset's interest start to now,
+// splitting the time into segments at every deposit, withdrawal, rate change and
+// compounding date. Within a segment the balance earns simple interest under the asset's
+// day-count convention, and what has accrued is added to the balance on compounding dates.
+// Deposits earn interest from the day they are made.
+func accruedInterest(asset *models.Asset, txs []*models.Transaction, rates []models.InterestRate, now time.Time) (float64, bool) {
+	start := day(asset.InterestStart)
+	if asset.InterestStart.IsZero() {
+		if len(txs) == 0 {
+			return 0, false
+		}
+		start = day(txs[0].Date)
+	}
+	end := day(now)
+	if end.Before(start) {
+		return 0, false
+	}
+
+	compounding := compoundingDates(asset.CompoundingFrequency, start, end)
+	dates := append([]time.Time{end}, compounding...)
+	for _, tx := range txs {
+		if d := day(tx.Date); d.After(start) && d.Before(end) {
+			dates = append(dates, d)
+		}
+	}
+	for _, rate := range rates {
+		if d := day(rate.EffectiveFrom); d.After(start) && d.Before(end) {
+			dates = append(dates, d)
+		}
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	dates = slices.CompactFunc(dates, func(a, b time.Time) bool { return a.Equal(b) })
+
+	var balance, accrued, earned float64
+	next, due := 0, 0
+	settle := func(through time.Time) {
+		for next < len(txs) && !day(txs[next].Date).After(through) {
+			switch txs[next].Type {
+			case models.TransactionDeposit:
+				balance += txs[next].Amount
+			case models.TransactionWithdrawal:
+				balance -= txs[next].Amount
+			}
+			next++
+		}
+	}
+
+	settle(start)
+	previous := start
+	for _, date := range dates {
+		accrued += balance * rateOn(asset, rates, previous) * yearFraction(asset.DayCount, previous, date)
+		// Compounding dates are among the sorted dates, so they come up in order
+		if due < len(compounding) && compounding[due].Equal(date) {
+			balance += accrued
+			earned += accrued
+			accrued = 0
+			due++
+		}
+		settle(date)
+		previous = date
+	}
+
+	return earned + accrued, true
+}
+
+// compoundingDates lists the dates after start, up to and including end, on which
+// accrued interest is added to the balance. Monthly dates of an account opened at the end
+// of a month fall on the last day of shorter months.
+func compoundingDates(frequency string, start, end time.Time) []time.Time {
+	months := 1
+	switch frequency {
+	case "daily":
+		months = 0
+	case "quarterly":
+		months = 3
+	case "annually":
+		months = 12
+	}
+
+	var dates []time.Time
+	for i := 1; ; i++ {
+		date := addMonths(start, i*months)
+		if months == 0 {
+			date = start.AddDate(0, 0, i)
+		}
+		if date.After(end) {
+			return dates
+		}
+		dates = append(dates, date)
+	}
+}
+
+// rateOn returns the annual rate, as a fraction, in force on date.
+func rateOn(asset *models.Asset, rates []models.InterestRate, date time.Time) float64 {
+	rate := asset.InterestRate
+	for _, change := range rates {
+		if !day(change.EffectiveFrom).After(date) {
+			rate = change.Rate
+		}
+	}
+	return float64(rate) / 100
+}
+
+// yearFraction is the part of a year between two days under a day-count convention:
+// act/365 (the default), act/360 or 30/360.
+func yearFraction(dayCount string, from, to time.Time) float64 {
+	switch dayCount {
+	case "act/360":
+		return to.Sub(from).Hours() / 24 / 360
+	case "30/360":
+		d1, d2 := from.Day(), to.Day()
+		if d1 == 31 {
+			d1 = 30
+		}
+		if d2 == 31 && d1 == 30 {
+			d2 = 30
+		}
+		days := 360*(to.Year()-from.Year()) + 30*int(to.Month()-from.Month()) + d2 - d1
+		return float64(days) / 360
+	default:
+		return to.Sub(from).Hours() / 24 / 365
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestYearFraction(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		dayCount string
+		from, to time.Time
+		want     float64
+	}{
+		{"", date(2024, 1, 1), date(2024, 7, 1), 182.0 / 365},
+		{"act/365", date(2024, 1, 1), date(2025, 1, 1), 366.0 / 365},
+		{"act/360", date(2024, 1, 1), date(2024, 7, 1), 182.0 / 360},
+		{"30/360", date(2024, 1, 1), date(2024, 7, 1), 0.5},
+		{"30/360", date(2024, 1, 31), date(2024, 3, 31), 60.0 / 360},
+		{"30/360", date(2024, 1, 30), date(2024, 2, 29), 29.0 / 360},
+		{"30/360", date(2024, 2, 15), date(2024, 3, 31), 46.0 / 360},
+	}
+
+	for _, tt := range tests {
+		if got := yearFraction(tt.dayCount, tt.from, tt.to); !near(got, tt.want) {
+			t.Errorf("yearFraction(%q, %s, %s) = %v, want %v", tt.dayCount,
+				tt.from.Format(time.DateOnly), tt.to.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestCompoundingDates(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		frequency  string
+		start, end time.Time
+		want       []time.Time
+	}{
+		{"monthly", date(2024, 1, 31), date(2024, 4, 30), []time.Time{date(2024, 2, 29), date(2024, 3, 31), date(2024, 4, 30)}},
+		{"quarterly", date(2024, 11, 30), date(2025, 6, 1), []time.Time{date(2025, 2, 28), date(2025, 5, 30)}},
+		{"annually", date(2024, 2, 29), date(2026, 3, 1), []time.Time{date(2025, 2, 28), date(2026, 2, 28)}},
+		{"daily", date(2024, 2, 28), date(2024, 3, 1), []time.Time{date(2024, 2, 29), date(2024, 3, 1)}},
+	}
+
+	for _, tt := range tests {
+		got := compoundingDates(tt.frequency, tt.start, tt.end)
+		if !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+			t.Errorf("compoundingDates(%q, %s, %s) = %v, want %v", tt.frequency,
+				tt.start.Format(time.DateOnly), tt.end.Format(time.DateOnly), got, tt.want)
+		}
+	}
+}
+
+func TestAccruedInterest(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	deposit := func(amount float64, on time.Time) *models.Transaction {
+		return &models.Transaction{Type: models.TransactionDeposit, Amount: amount, Date: on}
+	}
+	withdraw := func(amount float64, on time.Time) *models.Transaction {
+		return &models.Transaction{Type: models.TransactionWithdrawal, Amount: amount, Date: on}
+	}
+	// Two deposits and a rate cut split the half year into segments of 91, 30 and 61 days
+	txs := []*models.Transaction{deposit(1000, date(2024, 1, 1)), deposit(1000, date(2024, 4, 1))}
+	rates := []models.InterestRate{{Rate: 3, EffectiveFrom: date(2024, 5, 1)}}
+
+	tests := []struct {
+		name        string
+		dayCount    string
+		compounding string
+		txs         []*models.Transaction
+		rates       []models.InterestRate
+		now         time.Time
+		want        float64
+		wantOK      bool
+	}{
+		{
+			name:        "act/365 segments",
+			dayCount:    "act/365",
+			compounding: "annually",
+			txs:         txs,
+			rates:       rates,
+			now:         date(2024, 7, 1),
+			want:        (1000*0.05*91 + 2000*0.05*30 + 2000*0.03*61) / 365,
+			wantOK:      true,
+		},
+		{
+			name:        "act/360 segments",
+			dayCount:    "act/360",
+			compounding: "annually",
+			txs:         txs,
+			rates:       rates,
+			now:         date(2024, 7, 1),
+			want:        (1000*0.05*91 + 2000*0.05*30 + 2000*0.03*61) / 360,
+			wantOK:      true,
+		},
+		{
+			name:        "30/360 segments",
+			dayCount:    "30/360",
+			compounding: "annually",
+			txs:         txs,
+			rates:       rates,
+			now:         date(2024, 7, 1),
+			want:        (1000*0.05*90 + 2000*0.05*30 + 2000*0.03*60) / 360,
+			wantOK:      true,
+		},
+		{
+			name:        "a withdrawal stops interest on what was taken out",
+			dayCount:    "30/360",
+			compounding: "annually",
+			txs:         []*models.Transaction{deposit(1000, date(2024, 1, 1)), withdraw(400, date(2024, 4, 1))},
+			now:         date(2024, 7, 1),
+			want:        1000*0.05*90/360 + 600*0.05*90/360,
+			wantOK:      true,
+		},
+		{
+			name:        "monthly compounding earns interest on interest",
+			dayCount:    "30/360",
+			compounding: "monthly",
+			txs:         []*models.Transaction{deposit(1200, date(2024, 1, 1))},
+			now:         date(2024, 3, 1),
+			want:        1200*0.05/12 + (1200+1200*0.05/12)*0.05/12,
+			wantOK:      true,
+		},
+		{
+			name:        "monthly compounding from the end of a month",
+			dayCount:    "act/365",
+			compounding: "monthly",
+			txs:         []*models.Transaction{deposit(1200, date(2024, 1, 31))},
+			now:         date(2024, 3, 31),
+			// Compounds on Feb 29 and Mar 31, rather than on Mar 2
+			want:   1200*0.05*29/365 + (1200+1200*0.05*29/365)*0.05*31/365,
+			wantOK: true,
+		},
+		{
+			name:   "no interest before the first deposit",
+			txs:    []*models.Transaction{deposit(1000, date(2024, 1, 1))},
+			now:    date(2023, 12, 31),
+			wantOK: false,
+		},
+		{
+			name:   "no interest without a start",
+			now:    date(2024, 7, 1),
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset := &models.Asset{Type: "Savings", InterestRate: 5, DayCount: tt.dayCount, CompoundingFrequency: tt.compounding}
+
+			got, ok := accruedInterest(asset, tt.txs, tt.rates, tt.now)
+			if ok != tt.wantOK || !near(got, tt.want) {
+				t.Errorf("accruedInterest() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// LotMethod selects which open lots a sell is matched against.
+type LotMethod string
+
+const (
+	LotFIFO     LotMethod = "fifo"
+	LotLIFO     LotMethod = "lifo"
+	LotAverage  LotMethod = "average"
+	LotSpecific LotMethod = "specific"
+)
+
+// ParseLotMethod validates a lot method name, defaulting to FIFO when empty.
+func ParseLotMethod(name string) (LotMethod, error) {
+	switch method := LotMethod(name); method {
+	case "":
+		return LotFIFO, nil
+	case LotFIFO, LotLIFO, LotAverage, LotSpecific:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unknown lot method %q", name)
+	}
+}
+
+// lotBook holds the open lots of one asset while its ledger is replayed.
+type lotBook struct {
+	method   LotMethod
+	lots     []models.Lot
+	realized float64
+}
+
+func (b *lotBook) open(lot models.Lot) {
+	if b.method == LotAverage && len(b.lots) > 0 {
+		pooled := &b.lots[0]
+		cost := pooled.Quantity*pooled.UnitCost + lot.Quantity*lot.UnitCost
+		pooled.Quantity += lot.Quantity
+		pooled.UnitCost = cost / pooled.Quantity
+		return
+	}
+	b.lots = append(b.lots, lot)
+}
+
+// close removes quantity from the open lots at the given unit price and books the realized gain.
+func (b *lotBook) close(quantity, price, fee float64, lotID *int) {
+	if b.method == LotSpecific && lotID != nil {
+		if i := slices.IndexFunc(b.lots, func(l models.Lot) bool { return l.TransactionID == *lotID }); i >= 0 {
+			quantity = b.consume(i, quantity, price)
+		}
+	}
+
+	for quantity > 0 && len(b.lots) > 0 {
+		i := 0
+		if b.method == LotLIFO {
+			i = len(b.lots) - 1
+		}
+		quantity = b.consume(i, quantity, price)
+	}
+
+	b.realized -= fee
+}
+
+// consume closes up to quantity units of lot i and returns what is left to match.
+func (b *lotBook) consume(i int, quantity, price float64) float64 {
+	lot := &b.lots[i]
+	matched := min(quantity, lot.Quantity)
+	b.realized += matched * (price - lot.UnitCost)
+	lot.Quantity -= matched
+
+	if lot.Quantity <= 0 {
+		b.lots = slices.Delete(b.lots, i, i+1)
+	}
+	return quantity - matched
+}
+
+// BuildHolding replays the ledger of an asset in order and returns the
+// resulting position, matching sells to open lots with the given method.
+func BuildHolding(asset *models.Asset, txs []*models.Transaction, method LotMethod) models.Holding {
+	if len(txs) == 0 {
+		txs = openingTransactions(asset)
+	}
+
+	book := lotBook{method: method}
+	holding := models.Holding{AssetID: asset.ID}
+
+	for _, tx := range txs {
+		switch tx.Type {
+		case models.TransactionBuy:
+			book.open(models.Lot{
+				TransactionID: tx.ID,
+				Date:          tx.Date,
+				Quantity:      tx.Quantity,
+				UnitCost:      tx.Price + tx.Fee/tx.Quantity,
+			})
+		case models.TransactionSell:
+			book.close(tx.Quantity, tx.Price, tx.Fee, tx.LotID)
+		case models.TransactionDeposit:
+			book.open(models.Lot{TransactionID: tx.ID, Date: tx.Date, Quantity: tx.Amount, UnitCost: 1})
+		case models.TransactionWithdrawal:
+			book.close(tx.Amount, 1, 0, nil)
+		case models.TransactionFee:
+			holding.Fees += tx.Amount
+		case models.TransactionCoupon, models.TransactionDividend:
+			holding.Income += tx.Amount
+		}
+	}
+
+	for _, lot := range book.lots {
+		holding.Quantity += lot.Quantity
+		holding.CostBasis += lot.Quantity * lot.UnitCost
+	}
+	if holding.Quantity > 0 {
+		holding.AverageCost = holding.CostBasis / holding.Quantity
+	}
+	holding.Realized = book.realized
+	holding.Lots = book.lots
+
+	return holding
+}
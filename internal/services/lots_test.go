@@ -0,0 +1,129 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func buy(id int, quantity, price, fee float64) *models.Transaction {
+	return &models.Transaction{ID: id, Type: models.TransactionBuy, Quantity: quantity, Price: price, Fee: fee,
+		Date: time.Date(2024, 1, id, 0, 0, 0, 0, time.UTC)}
+}
+
+func sell(id int, quantity, price, fee float64, lotID *int) *models.Transaction {
+	return &models.Transaction{ID: id, Type: models.TransactionSell, Quantity: quantity, Price: price, Fee: fee,
+		LotID: lotID, Date: time.Date(2024, 1, id, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestBuildHolding(t *testing.T) {
+	lot := func(id int) *int { return &id }
+	// Three lots of 10 at 100, 120 and 90
+	lots := []*models.Transaction{buy(1, 10, 100, 0), buy(2, 10, 120, 0), buy(3, 10, 90, 0)}
+
+	tests := []struct {
+		name          string
+		method        LotMethod
+		txs           []*models.Transaction
+		wantQuantity  float64
+		wantCostBasis float64
+		wantRealized  float64
+		wantLots      int
+	}{
+		{
+			name:          "fifo sells the oldest lot",
+			method:        LotFIFO,
+			txs:           append(lots, sell(4, 5, 130, 0, nil)),
+			wantQuantity:  25,
+			wantCostBasis: 500 + 1200 + 900,
+			wantRealized:  5 * 30,
+			wantLots:      3,
+		},
+		{
+			name:          "lifo sells the newest lot",
+			method:        LotLIFO,
+			txs:           append(lots, sell(4, 5, 130, 0, nil)),
+			wantQuantity:  25,
+			wantCostBasis: 1000 + 1200 + 450,
+			wantRealized:  5 * 40,
+			wantLots:      3,
+		},
+		{
+			name:          "average pools every lot",
+			method:        LotAverage,
+			txs:           append(lots, sell(4, 5, 130, 0, nil)),
+			wantQuantity:  25,
+			wantCostBasis: 25 * 3100.0 / 30,
+			wantRealized:  5 * (130 - 3100.0/30),
+			wantLots:      1,
+		},
+		{
+			name:          "specific sells the named lot",
+			method:        LotSpecific,
+			txs:           append(lots, sell(4, 5, 130, 0, lot(2))),
+			wantQuantity:  25,
+			wantCostBasis: 1000 + 600 + 900,
+			wantRealized:  5 * 10,
+			wantLots:      3,
+		},
+		{
+			name:          "specific takes the rest from the oldest lots",
+			method:        LotSpecific,
+			txs:           append(lots, sell(4, 15, 130, 0, lot(2))),
+			wantQuantity:  15,
+			wantCostBasis: 500 + 900,
+			wantRealized:  10*10 + 5*30,
+			wantLots:      2,
+		},
+		{
+			name:          "specific without a lot falls back to fifo",
+			method:        LotSpecific,
+			txs:           append(lots, sell(4, 5, 130, 0, nil)),
+			wantQuantity:  25,
+			wantCostBasis: 500 + 1200 + 900,
+			wantRealized:  5 * 30,
+			wantLots:      3,
+		},
+		{
+			name:         "fees raise the cost and lower the proceeds",
+			method:       LotFIFO,
+			txs:          []*models.Transaction{buy(1, 10, 100, 10), sell(2, 10, 110, 5, nil)},
+			wantRealized: 10*(110-101) - 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			holding := BuildHolding(&models.Asset{ID: 1, Type: "Stock"}, tt.txs, tt.method)
+
+			if !near(holding.Quantity, tt.wantQuantity) {
+				t.Errorf("Quantity = %v, want %v", holding.Quantity, tt.wantQuantity)
+			}
+			if !near(holding.CostBasis, tt.wantCostBasis) {
+				t.Errorf("CostBasis = %v, want %v", holding.CostBasis, tt.wantCostBasis)
+			}
+			if !near(holding.Realized, tt.wantRealized) {
+				t.Errorf("Realized = %v, want %v", holding.Realized, tt.wantRealized)
+			}
+			if len(holding.Lots) != tt.wantLots {
+				t.Errorf("%d open lots, want %d", len(holding.Lots), tt.wantLots)
+			}
+		})
+	}
+}
+
+func TestBuildHoldingOpeningPosition(t *testing.T) {
+	asset := &models.Asset{ID: 1, Type: "Stock", Price: 50, Amount: 4}
+
+	holding := BuildHolding(asset, nil, LotFIFO)
+	if holding.Quantity != 4 || holding.CostBasis != 200 {
+		t.Errorf("holding of an asset without a ledger = %v at %v, want 4 at 200", holding.Quantity, holding.CostBasis)
+	}
+}
+
+// near compares money amounts, which pick up float rounding along the way.
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
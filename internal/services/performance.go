@@ -0,0 +1,285 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// PerformanceService measures how the money invested in the portfolio performed over a
+// period, using the daily P&L in asset_returns and the cash flows in the ledgers.
+type PerformanceService struct {
+	assetRepo    *repositories.AssetRepository
+	assets       *AssetService
+	historicRepo *repositories.AssetReturnHistoryRepository
+	converter    *CurrencyConverter
+}
+
+func NewPerformanceService(assetRepo *repositories.AssetRepository,
+	assets *AssetService,
+	historicRepo *repositories.AssetReturnHistoryRepository,
+	converter *CurrencyConverter) *PerformanceService {
+	return &PerformanceService{assetRepo: assetRepo, assets: assets, historicRepo: historicRepo, converter: converter}
+}
+
+// Performance reports TWR, MWR and CAGR per asset, per asset type and for the whole
+// portfolio between from and to. Cash flows are converted at today's exchange rates,
+// the same way the recorded P&L was.
+func (p *PerformanceService) Performance(ctx context.Context, from, to time.Time) (models.PerformanceReport, error) {
+	assets, series, err := p.seriesByAsset(ctx, repositories.ReturnFilter{From: from, To: to})
+	if err != nil {
+		return models.PerformanceReport{}, err
+	}
+
+	report := models.PerformanceReport{
+		From:         from,
+		To:           to,
+		BaseCurrency: p.converter.Base,
+		ByType:       make(map[string]models.Performance),
+		ByAsset:      make(map[int]models.Performance),
+	}
+
+	byType := make(map[string][][]models.ValuePoint)
+	var all [][]models.ValuePoint
+	for _, asset := range assets {
+		points := series[asset.ID]
+		if len(points) == 0 {
+			continue
+		}
+		report.ByAsset[asset.ID] = measure(points)
+		byType[asset.Type] = append(byType[asset.Type], points)
+		all = append(all, points)
+	}
+	for assetType, group := range byType {
+		report.ByType[assetType] = measure(combineSeries(group))
+	}
+	report.Portfolio = measure(combineSeries(all))
+
+	return report, nil
+}
+
+// PortfolioSeries returns the daily value of the whole portfolio between from and to.
+func (p *PerformanceService) PortfolioSeries(ctx context.Context, from, to time.Time) ([]models.ValuePoint, error) {
+	assets, series, err := p.seriesByAsset(ctx, repositories.ReturnFilter{From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+
+	var all [][]models.ValuePoint
+	for _, asset := range assets {
+		if points := series[asset.ID]; len(points) > 0 {
+			all = append(all, points)
+		}
+	}
+	return combineSeries(all), nil
+}
+
+// seriesByAsset builds the daily value series of every asset matching filter.
+func (p *PerformanceService) seriesByAsset(ctx context.Context, filter repositories.ReturnFilter) ([]*models.Asset, map[int][]models.ValuePoint, error) {
+	assets, err := p.assetRepo.GetAllAssets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ledgers, err := p.assets.TxRepo.GetAllTransactions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	returns, err := p.historicRepo.GetAssetReturns(ctx, filter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	byAsset := make(map[int][]models.AssetReturn)
+	for _, ar := range returns {
+		byAsset[ar.AssetID] = append(byAsset[ar.AssetID], ar)
+	}
+
+	series := make(map[int][]models.ValuePoint)
+	for _, asset := range assets {
+		if len(byAsset[asset.ID]) == 0 {
+			continue
+		}
+		rate, err := p.converter.Convert(1, asset.Currency, p.converter.Base)
+		if err != nil {
+			return nil, nil, err
+		}
+		txs := ledgers[asset.ID]
+		if len(txs) == 0 {
+			txs = openingTransactions(asset)
+		}
+		series[asset.ID] = valueSeries(txs, byAsset[asset.ID], rate)
+	}
+	return assets, series, nil
+}
+
+// valueSeries turns the recorded daily P&L of an asset into its value on each of those
+// days: the P&L plus the money put into the position up to that day. Flows before the
+// first day are part of the starting value rather than flows of the period.
+func valueSeries(txs []*models.Transaction, returns []models.AssetReturn, rate float64) []models.ValuePoint {
+	var points []models.ValuePoint
+	invested, flow := 0.0, 0.0
+	next := 0
+
+	for _, ar := range returns {
+		date := day(ar.Date)
+		for next < len(txs) && !day(txs[next].Date).After(date) {
+			f := netFlow(txs[next]) * rate
+			invested += f
+			flow += f
+			next++
+		}
+
+		if n := len(points); n > 0 && points[n-1].Date.Equal(date) {
+			points[n-1].Value = ar.Returns + invested
+			points[n-1].Flow += flow
+		} else {
+			if n == 0 {
+				flow = 0
+			}
+			points = append(points, models.ValuePoint{Date: date, Value: ar.Returns + invested, Flow: flow})
+		}
+		flow = 0
+	}
+	return points
+}
+
+// combineSeries adds up the value series of several positions. A position without a
+// point on some day counts at its last known value, and a position that first appears
+// after the combined series started is treated as money put in on that day.
+func combineSeries(series [][]models.ValuePoint) []models.ValuePoint {
+	var dates []time.Time
+	for _, points := range series {
+		for _, point := range points {
+			dates = append(dates, point.Date)
+		}
+	}
+	slices.SortFunc(dates, func(a, b time.Time) int { return a.Compare(b) })
+	dates = slices.CompactFunc(dates, func(a, b time.Time) bool { return a.Equal(b) })
+
+	cursors := make([]int, len(series))
+	last := make([]float64, len(series))
+	combined := make([]models.ValuePoint, 0, len(dates))
+
+	for i, date := range dates {
+		point := models.ValuePoint{Date: date}
+		for s, points := range series {
+			if c := cursors[s]; c < len(points) && points[c].Date.Equal(date) {
+				last[s] = points[c].Value
+				point.Flow += points[c].Flow
+				if c == 0 && i > 0 {
+					point.Flow += points[c].Value
+				}
+				cursors[s]++
+			}
+			point.Value += last[s]
+		}
+		combined = append(combined, point)
+	}
+	return combined
+}
+
+// measure computes the performance of a value series over its whole span.
+func measure(points []models.ValuePoint) models.Performance {
+	if len(points) == 0 {
+		return models.Performance{}
+	}
+
+	first, final := points[0], points[len(points)-1]
+	perf := models.Performance{
+		From:       first.Date,
+		To:         final.Date,
+		StartValue: first.Value,
+		EndValue:   final.Value,
+		TWR:        timeWeightedReturn(points),
+	}
+	for _, point := range points[1:] {
+		perf.NetFlows += point.Flow
+	}
+	perf.Gain = perf.EndValue - perf.StartValue - perf.NetFlows
+
+	if years := final.Date.Sub(first.Date).Hours() / 24 / 365; years > 0 {
+		cagr := math.Pow(1+perf.TWR, 1/years) - 1
+		perf.CAGR = &cagr
+	}
+	if mwr, ok := xirr(points); ok {
+		perf.MWR = &mwr
+	}
+	return perf
+}
+
+// periodReturn is the return of a value series between a point and the one before it.
+type periodReturn struct {
+	Date   time.Time
+	Return float64
+}
+
+// periodReturns returns the return between each pair of consecutive points. Money put in
+// on a day is taken to arrive at that day's closing value, so it earns nothing that day.
+// Periods starting from nothing have no return and are left out.
+func periodReturns(points []models.ValuePoint) []periodReturn {
+	var returns []periodReturn
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Value <= 0 {
+			continue
+		}
+		returns = append(returns, periodReturn{
+			Date:   points[i].Date,
+			Return: (points[i].Value-points[i].Flow)/points[i-1].Value - 1,
+		})
+	}
+	return returns
+}
+
+// timeWeightedReturn chains the period returns of a value series.
+func timeWeightedReturn(points []models.ValuePoint) float64 {
+	growth := 1.0
+	for _, r := range periodReturns(points) {
+		growth *= 1 + r.Return
+	}
+	return growth - 1
+}
+
+// xirr finds the annual rate at which the cash flows of a value series, with the starting
+// value as the first outlay and the final value as the last inflow, have a net present
+// value of zero. It reports false when no such rate exists in a sensible range.
+func xirr(points []models.ValuePoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+
+	start := points[0].Date
+	npv := func(rate float64) float64 {
+		total := 0.0
+		for i, point := range points {
+			years := point.Date.Sub(start).Hours() / 24 / 365
+			cash := -point.Flow
+			if i == 0 {
+				cash = -point.Value
+			}
+			if i == len(points)-1 {
+				cash += point.Value
+			}
+			total += cash / math.Pow(1+rate, years)
+		}
+		return total
+	}
+
+	low, high := -0.9999, 100.0
+	lowNPV, highNPV := npv(low), npv(high)
+	if math.IsNaN(lowNPV) || math.IsNaN(highNPV) || lowNPV*highNPV > 0 {
+		return 0, false
+	}
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+		if midNPV := npv(mid); (midNPV > 0) == (lowNPV > 0) {
+			low, lowNPV = mid, midNPV
+		} else {
+			high = mid
+		}
+	}
+	return (low + high) / 2, true
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestMeasure(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := func(days int) time.Time { return start.AddDate(0, 0, days) }
+
+	tests := []struct {
+		name     string
+		points   []models.ValuePoint
+		wantTWR  float64
+		wantCAGR *float64
+		wantGain float64
+		wantMWR  bool
+	}{
+		{
+			name:     "one year without flows",
+			points:   []models.ValuePoint{{Date: on(0), Value: 100}, {Date: on(365), Value: 110}},
+			wantTWR:  0.10,
+			wantCAGR: ptr(0.10),
+			wantGain: 10,
+			wantMWR:  true,
+		},
+		{
+			name: "a deposit halfway does not count as return",
+			points: []models.ValuePoint{
+				{Date: on(0), Value: 100},
+				{Date: on(182), Value: 210, Flow: 100},
+				{Date: on(365), Value: 231},
+			},
+			wantTWR:  1.1*1.1 - 1,
+			wantCAGR: ptr(1.1*1.1 - 1),
+			wantGain: 31,
+			wantMWR:  true,
+		},
+		{
+			name:     "two years annualize",
+			points:   []models.ValuePoint{{Date: on(0), Value: 100}, {Date: on(730), Value: 121}},
+			wantTWR:  0.21,
+			wantCAGR: ptr(0.10),
+			wantGain: 21,
+			wantMWR:  true,
+		},
+		{
+			name:    "a single point has no rate",
+			points:  []models.ValuePoint{{Date: on(0), Value: 100}},
+			wantTWR: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			perf := measure(tt.points)
+
+			if !near(perf.TWR, tt.wantTWR) {
+				t.Errorf("TWR = %v, want %v", perf.TWR, tt.wantTWR)
+			}
+			if !near(perf.Gain, tt.wantGain) {
+				t.Errorf("Gain = %v, want %v", perf.Gain, tt.wantGain)
+			}
+			switch {
+			case tt.wantCAGR == nil && perf.CAGR != nil:
+				t.Errorf("CAGR = %v, want none", *perf.CAGR)
+			case tt.wantCAGR != nil && (perf.CAGR == nil || !near(*perf.CAGR, *tt.wantCAGR)):
+				t.Errorf("CAGR = %v, want %v", perf.CAGR, *tt.wantCAGR)
+			}
+			if (perf.MWR != nil) != tt.wantMWR {
+				t.Fatalf("MWR = %v, want one: %v", perf.MWR, tt.wantMWR)
+			}
+			if perf.MWR != nil {
+				if npv := netPresentValue(tt.points, *perf.MWR); math.Abs(npv) > 1e-6 {
+					t.Errorf("MWR %v leaves a net present value of %v", *perf.MWR, npv)
+				}
+			}
+		})
+	}
+}
+
+func TestXIRRWithoutFlowsEqualsCAGR(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []models.ValuePoint{{Date: start, Value: 1000}, {Date: start.AddDate(0, 0, 730), Value: 1210}}
+
+	mwr, ok := xirr(points)
+	if !ok || math.Abs(mwr-0.10) > 1e-9 {
+		t.Errorf("xirr() = %v, %v, want 0.10", mwr, ok)
+	}
+}
+
+func TestXIRRWithoutSolution(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Money was only ever taken out, so no rate discounts the flows to zero
+	points := []models.ValuePoint{{Date: start, Value: 0}, {Date: start.AddDate(0, 0, 30), Value: 50, Flow: -50}}
+
+	if mwr, ok := xirr(points); ok {
+		t.Errorf("xirr() = %v, want no solution", mwr)
+	}
+}
+
+// netPresentValue discounts the cash flows of a value series the way xirr does.
+func netPresentValue(points []models.ValuePoint, rate float64) float64 {
+	total := 0.0
+	for i, point := range points {
+		years := point.Date.Sub(points[0].Date).Hours() / 24 / 365
+		cash := -point.Flow
+		if i == 0 {
+			cash = -point.Value
+		}
+		if i == len(points)-1 {
+			cash += point.Value
+		}
+		total += cash / math.Pow(1+rate, years)
+	}
+	return total
+}
+
+func ptr(f float64) *float64 {
+	return &f
+}
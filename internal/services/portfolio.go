@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// ErrPortfolioNotEmpty is returned when deleting a portfolio that still holds assets.
+var ErrPortfolioNotEmpty = errors.New("portfolio still holds assets")
+
+// PortfolioService manages the portfolios a user splits their assets into.
+type PortfolioService struct {
+	Repo *repositories.PortfolioRepository
+	// LotMethod is used for portfolios created or updated without one
+	LotMethod LotMethod
+}
+
+func NewPortfolioService(repo *repositories.PortfolioRepository, lotMethod LotMethod) *PortfolioService {
+	return &PortfolioService{Repo: repo, LotMethod: lotMethod}
+}
+
+func (s *PortfolioService) CreatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	return s.Repo.AddPortfolio(ctx, p)
+}
+
+func (s *PortfolioService) UpdatePortfolio(ctx context.Context, p *models.Portfolio) error {
+	if err := s.validate(p); err != nil {
+		return err
+	}
+	return s.Repo.UpdatePortfolio(ctx, p)
+}
+
+// validate checks that a portfolio is named and has a known lot method, filling in the default.
+func (s *PortfolioService) validate(p *models.Portfolio) error {
+	p.Name = strings.TrimSpace(p.Name)
+	if p.Name == "" {
+		return errors.New("portfolio name is required")
+	}
+	if p.LotMethod == "" {
+		p.LotMethod = string(s.LotMethod)
+	}
+	method, err := ParseLotMethod(p.LotMethod)
+	if err != nil {
+		return err
+	}
+	p.LotMethod = string(method)
+	return nil
+}
+
+// DeletePortfolio removes a portfolio once its assets have been moved or deleted.
+func (s *PortfolioService) DeletePortfolio(ctx context.Context, id int) error {
+	if _, err := s.Repo.GetPortfolioByID(ctx, id); err != nil {
+		return err
+	}
+	count, err := s.Repo.CountAssets(ctx, id)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrPortfolioNotEmpty
+	}
+	return s.Repo.DeletePortfolio(ctx, id)
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// GoldSymbol is the symbol gold is quoted under, since gold assets carry no ticker.
+const GoldSymbol = "XAU"
+
+// PriceProvider is a source of current prices. Providers that can quote in a
+// requested currency do so, the others quote in the symbol's listing currency.
+type PriceProvider interface {
+	Name() string
+	Quote(ctx context.Context, symbol, currency string) (models.Quote, error)
+}
+
+// PriceRegistry holds, per asset type, the providers to ask for a price in order of preference.
+type PriceRegistry struct {
+	providers map[string][]PriceProvider
+}
+
+func NewPriceRegistry() *PriceRegistry {
+	return &PriceRegistry{providers: make(map[string][]PriceProvider)}
+}
+
+// Register appends providers to the fallback chain of an asset type.
+func (r *PriceRegistry) Register(assetType string, providers ...PriceProvider) {
+	r.providers[assetType] = append(r.providers[assetType], providers...)
+}
+
+// Quote asks each provider registered for the asset type in turn and returns
+// the first quote obtained, or every provider's error if none succeeds.
+func (r *PriceRegistry) Quote(ctx context.Context, assetType, symbol, currency string) (models.Quote, error) {
+	providers := r.providers[assetType]
+	if len(providers) == 0 {
+		return models.Quote{}, fmt.Errorf("no price provider registered for %s", assetType)
+	}
+
+	var errs []error
+	for _, provider := range providers {
+		quote, err := provider.Quote(ctx, symbol, currency)
+		if err == nil {
+			quote.Source = provider.Name()
+			return quote, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	return models.Quote{}, fmt.Errorf("no price for %s %s: %w", assetType, symbol, errors.Join(errs...))
+}
+
+// QuoteSymbol returns the symbol an asset is priced, cached and stored under.
+func QuoteSymbol(asset *models.Asset) string {
+	switch asset.Type {
+	case "Gold":
+		return GoldSymbol
+	case "Crypto":
+		return strings.ToLower(asset.Ticker)
+	}
+	return asset.Ticker
+}
+
+// HistoryProvider is a PriceProvider that can also serve daily closing prices.
+type HistoryProvider interface {
+	PriceProvider
+	History(ctx context.Context, symbol, currency string, from, to time.Time) ([]models.Quote, error)
+}
+
+// History asks each provider of the asset type that serves history in turn and
+// returns the daily closes of the first one that succeeds.
+func (r *PriceRegistry) History(ctx context.Context, assetType, symbol, currency string, from, to time.Time) ([]models.Quote, error) {
+	var errs []error
+	for _, provider := range r.providers[assetType] {
+		historyProvider, ok := provider.(HistoryProvider)
+		if !ok {
+			continue
+		}
+
+		quotes, err := historyProvider.History(ctx, symbol, currency, from, to)
+		if err == nil {
+			for i := range quotes {
+				quotes[i].Source = provider.Name()
+			}
+			return quotes, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no price history provider registered for %s", assetType)
+	}
+	return nil, fmt.Errorf("no price history for %s %s: %w", assetType, symbol, errors.Join(errs...))
+}
+
+// DividendProvider is a PriceProvider that can also list the dividends a symbol paid.
+type DividendProvider interface {
+	PriceProvider
+	Dividends(ctx context.Context, symbol string, from, to time.Time) ([]models.Dividend, error)
+}
+
+// Dividends asks each provider of the asset type that lists dividends in turn and
+// returns the dividends of the first one that succeeds.
+func (r *PriceRegistry) Dividends(ctx context.Context, assetType, symbol string, from, to time.Time) ([]models.Dividend, error) {
+	var errs []error
+	for _, provider := range r.providers[assetType] {
+		dividendProvider, ok := provider.(DividendProvider)
+		if !ok {
+			continue
+		}
+
+		dividends, err := dividendProvider.Dividends(ctx, symbol, from, to)
+		if err == nil {
+			return dividends, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no dividend provider registered for %s", assetType)
+	}
+	return nil, fmt.Errorf("no dividends for %s %s: %w", assetType, symbol, errors.Join(errs...))
+}
+
+// day truncates t to midnight UTC of its calendar day, the timestamp daily closes are stored under.
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
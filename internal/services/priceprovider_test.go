@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// fakeProvider quotes a fixed price per symbol and fails for any other symbol.
+type fakeProvider struct {
+	name   string
+	prices map[string]float64
+	calls  int
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) Quote(ctx context.Context, symbol, currency string) (models.Quote, error) {
+	p.calls++
+	price, ok := p.prices[symbol]
+	if !ok {
+		return models.Quote{}, errors.New("unknown symbol")
+	}
+	return models.Quote{Symbol: symbol, Price: price, Currency: currency}, nil
+}
+
+// fakeHistoryProvider also serves one daily close per price.
+type fakeHistoryProvider struct {
+	fakeProvider
+}
+
+func (p *fakeHistoryProvider) History(ctx context.Context, symbol, currency string, from, to time.Time) ([]models.Quote, error) {
+	quote, err := p.Quote(ctx, symbol, currency)
+	if err != nil {
+		return nil, err
+	}
+	quote.Timestamp = from
+	return []models.Quote{quote}, nil
+}
+
+func TestPriceRegistryQuote(t *testing.T) {
+	tests := []struct {
+		name       string
+		providers  []*fakeProvider
+		symbol     string
+		wantPrice  float64
+		wantSource string
+		wantCalls  []int
+		wantErr    string
+	}{
+		{
+			name: "primary answers",
+			providers: []*fakeProvider{
+				{name: "primary", prices: map[string]float64{"AAPL": 190}},
+				{name: "fallback", prices: map[string]float64{"AAPL": 180}},
+			},
+			symbol:     "AAPL",
+			wantPrice:  190,
+			wantSource: "primary",
+			wantCalls:  []int{1, 0},
+		},
+		{
+			name: "falls back when the primary fails",
+			providers: []*fakeProvider{
+				{name: "primary", prices: map[string]float64{}},
+				{name: "fallback", prices: map[string]float64{"AAPL": 180}},
+			},
+			symbol:     "AAPL",
+			wantPrice:  180,
+			wantSource: "fallback",
+			wantCalls:  []int{1, 1},
+		},
+		{
+			name: "reports every provider when all fail",
+			providers: []*fakeProvider{
+				{name: "primary", prices: map[string]float64{}},
+				{name: "fallback", prices: map[string]float64{}},
+			},
+			symbol:    "AAPL",
+			wantCalls: []int{1, 1},
+			wantErr:   "no price for Stock AAPL: primary: unknown symbol\nfallback: unknown symbol",
+		},
+		{
+			name:    "no providers",
+			symbol:  "AAPL",
+			wantErr: "no price provider registered for Stock",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewPriceRegistry()
+			for _, p := range tt.providers {
+				registry.Register("Stock", p)
+			}
+
+			quote, err := registry.Quote(context.Background(), "Stock", tt.symbol, "EUR")
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Quote() error = %v, want %q", err, tt.wantErr)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("Quote() error = %v", err)
+				}
+				if quote.Price != tt.wantPrice || quote.Source != tt.wantSource {
+					t.Errorf("Quote() = %v from %q, want %v from %q", quote.Price, quote.Source, tt.wantPrice, tt.wantSource)
+				}
+			}
+
+			for i, p := range tt.providers {
+				if p.calls != tt.wantCalls[i] {
+					t.Errorf("provider %s called %d times, want %d", p.name, p.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPriceRegistryHistorySkipsQuoteOnlyProviders(t *testing.T) {
+	quoteOnly := &fakeProvider{name: "quotes", prices: map[string]float64{"bitcoin": 1}}
+	failing := &fakeHistoryProvider{fakeProvider{name: "failing", prices: map[string]float64{}}}
+	history := &fakeHistoryProvider{fakeProvider{name: "history", prices: map[string]float64{"bitcoin": 60000}}}
+
+	registry := NewPriceRegistry()
+	registry.Register("Crypto", quoteOnly, failing, history)
+
+	quotes, err := registry.History(context.Background(), "Crypto", "bitcoin", "EUR", time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if len(quotes) != 1 || quotes[0].Price != 60000 || quotes[0].Source != "history" {
+		t.Errorf("History() = %+v, want one close of 60000 from history", quotes)
+	}
+	if quoteOnly.calls != 0 {
+		t.Errorf("quote-only provider was asked for history")
+	}
+
+	registry = NewPriceRegistry()
+	registry.Register("Crypto", quoteOnly)
+	_, err = registry.History(context.Background(), "Crypto", "bitcoin", "EUR", time.Now(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "no price history provider registered") {
+		t.Errorf("History() error = %v, want no history provider", err)
+	}
+}
+
+func TestQuoteSymbol(t *testing.T) {
+	tests := []struct {
+		asset models.Asset
+		want  string
+	}{
+		{models.Asset{Type: "Stock", Ticker: "AAPL"}, "AAPL"},
+		{models.Asset{Type: "Gold"}, GoldSymbol},
+		{models.Asset{Type: "Crypto", Ticker: "Bitcoin"}, "bitcoin"},
+	}
+
+	for _, tt := range tests {
+		if got := QuoteSymbol(&tt.asset); got != tt.want {
+			t.Errorf("QuoteSymbol(%s %q) = %q, want %q", tt.asset.Type, tt.asset.Ticker, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// periodStarts maps each supported granularity to the start of the period a day falls in.
+var periodStarts = map[string]func(time.Time) time.Time{
+	"daily": day,
+	"weekly": func(t time.Time) time.Time {
+		t = day(t)
+		return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+	},
+	"monthly": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	},
+	"quarterly": func(t time.Time) time.Time {
+		return time.Date(t.Year(), t.Month()-(t.Month()-1)%3, 1, 0, 0, 0, 0, time.UTC)
+	},
+	"yearly": func(t time.Time) time.Time {
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	},
+}
+
+// ReturnHistory splits the returns of the assets matching filter into daily, weekly,
+// monthly, quarterly or yearly periods, per asset and for all of them together.
+func (p *PerformanceService) ReturnHistory(ctx context.Context, filter repositories.ReturnFilter, granularity string) (models.ReturnHistory, error) {
+	periodStart, ok := periodStarts[granularity]
+	if !ok {
+		return models.ReturnHistory{}, fmt.Errorf("%w %q, expected daily, weekly, monthly, quarterly or yearly",
+			ErrUnknownInterval, granularity)
+	}
+
+	assets, series, err := p.seriesByAsset(ctx, filter)
+	if err != nil {
+		return models.ReturnHistory{}, err
+	}
+
+	history := models.ReturnHistory{
+		Granularity:  granularity,
+		From:         filter.From,
+		To:           filter.To,
+		BaseCurrency: p.converter.Base,
+		Total:        []models.PeriodReturn{},
+		Assets:       make(map[int][]models.PeriodReturn),
+	}
+
+	var all [][]models.ValuePoint
+	for _, asset := range assets {
+		points := series[asset.ID]
+		if len(points) == 0 {
+			continue
+		}
+		history.Assets[asset.ID] = periodReturnsBy(points, periodStart)
+		all = append(all, points)
+	}
+	if len(all) > 0 {
+		history.Total = periodReturnsBy(combineSeries(all), periodStart)
+	}
+	return history, nil
+}
+
+// periodReturnsBy groups the points of a value series into the periods periodStart assigns
+// them to and measures each period from the last point before it.
+func periodReturnsBy(points []models.ValuePoint, periodStart func(time.Time) time.Time) []models.PeriodReturn {
+	var periods []models.PeriodReturn
+	growth := 1.0
+
+	closePeriod := func() {
+		last := &periods[len(periods)-1]
+		last.Change = last.EndValue - last.StartValue - last.NetFlows
+		last.Percent = growth - 1
+	}
+
+	for i, point := range points {
+		start := periodStart(point.Date)
+		if n := len(periods); n == 0 || !periods[n-1].Period.Equal(start) {
+			if n > 0 {
+				closePeriod()
+			}
+			growth = 1
+			reference := point
+			if i > 0 {
+				reference = points[i-1]
+			}
+			periods = append(periods, models.PeriodReturn{
+				Period:     start,
+				From:       reference.Date,
+				StartValue: reference.Value,
+			})
+		}
+
+		current := &periods[len(periods)-1]
+		current.To = point.Date
+		current.EndValue = point.Value
+		if i > 0 {
+			current.NetFlows += point.Flow
+			if points[i-1].Value > 0 {
+				growth *= (point.Value - point.Flow) / points[i-1].Value
+			}
+		}
+	}
+	if len(periods) > 0 {
+		closePeriod()
+	}
+	return periods
+}
@@ -2,137 +2,250 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
-	"math"
+	"maps"
+	"strings"
 	"time"
 
+	"github.com/jagac/pfinance/internal/models"
 	"github.com/jagac/pfinance/internal/repositories"
 	"github.com/jagac/pfinance/pkg/cache"
 	"github.com/jagac/pfinance/pkg/worker"
 )
 
 type ReturnsCalculator struct {
-	Repo     *repositories.AssetRepository
-	cache    *cache.Cache[string, worker.TaskResult]
-	HistRepo *repositories.AssetReturnHistoryRepository
+	Repo      *repositories.AssetRepository
+	Assets    *AssetService
+	Converter *CurrencyConverter
+	cache     *cache.Cache[string, worker.TaskResult]
+	HistRepo  *repositories.AssetReturnHistoryRepository
+	PriceRepo *repositories.PriceRepository
 }
 
-func NewReturnsCalculator(Repo *repositories.AssetRepository,
-	cache *cache.Cache[string, worker.TaskResult], HistRepo *repositories.AssetReturnHistoryRepository) *ReturnsCalculator {
-	return &ReturnsCalculator{Repo: Repo, cache: cache, HistRepo: HistRepo}
+func NewReturnsCalculator(Repo *repositories.AssetRepository, Assets *AssetService, Converter *CurrencyConverter,
+	cache *cache.Cache[string, worker.TaskResult], HistRepo *repositories.AssetReturnHistoryRepository,
+	PriceRepo *repositories.PriceRepository) *ReturnsCalculator {
+	return &ReturnsCalculator{Repo: Repo, Assets: Assets, Converter: Converter, cache: cache, HistRepo: HistRepo, PriceRepo: PriceRepo}
 }
 
-// StockReturns calculates the stock P&L grouped by ticker.
-func (r *ReturnsCalculator) StockReturns() (map[int]float32, error) {
-	// Fetch the list of stock assets
-	stocks, err := r.Repo.GetAssetsByType(context.Background(), "Stock")
+// StockValuations marks every stock at its cached price in both its own and the base currency.
+func (r *ReturnsCalculator) StockValuations(ctx context.Context) (map[int]models.Valuation, error) {
+	return r.marketValuations(ctx, "Stock", "stockPrice")
+}
+
+// marketValuations marks every asset of a type at the quote its price task cached under
+// cacheKey, falling back to the latest persisted quote while the cache is cold.
+func (r *ReturnsCalculator) marketValuations(ctx context.Context, assetType, cacheKey string) (map[int]models.Valuation, error) {
+	// Fetch the list of assets and their positions
+	assets, holdings, err := r.Assets.GetHoldingsByType(ctx, assetType)
 	if err != nil {
 		return nil, err
 	}
 
-	pnlByTicker := make(map[int]float32)
-	stockPrices, ok := r.cache.Get("stockPrice")
-	if !ok {
-		return nil, fmt.Errorf("stock prices cache not found")
+	valuations := make(map[int]models.Valuation)
+	if len(assets) == 0 {
+		return valuations, nil
 	}
-	stockPricesRaw, ok := stockPrices.Value.(map[string]float32)
-	if !ok {
-		return nil, fmt.Errorf("stock prices cache is not in expected format")
+
+	// Prices fetched before a restart are only in the database until the price task runs again
+	quotes := make(map[string]models.Quote)
+	if cached, ok := r.cache.Get(cacheKey); ok {
+		if cachedQuotes, ok := cached.Value.(map[string]models.Quote); ok {
+			quotes = cachedQuotes
+		}
 	}
 
-	for _, stock := range stocks {
-		currentPrice, exists := stockPricesRaw[stock.Ticker] // Use Ticker instead of ID
+	for _, asset := range assets {
+		symbol := QuoteSymbol(asset)
+		quote, exists := quotes[symbol]
 		if !exists {
-			return nil, fmt.Errorf("price for ticker %s not found in cache", stock.Ticker)
+			quote, err = r.PriceRepo.GetLatestQuote(ctx, assetType, symbol)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("no %s price for %s in cache or price history", strings.ToLower(assetType), symbol)
+			}
+			if err != nil {
+				return nil, err
+			}
+		}
+		valuations[asset.ID], err = r.Converter.Valuate(asset, holdings[asset.ID], quote)
+		if err != nil {
+			return nil, err
 		}
-		pnl := (currentPrice - stock.Price) * stock.Amount
-		pnlByTicker[stock.ID] += pnl
 	}
 
-	return pnlByTicker, nil
+	return valuations, nil
 }
 
-// CalculateInterestPL calculates the interest P&L for assets with interest-bearing properties.
-func (r *ReturnsCalculator) CalculateInterestPL() (map[int]float32, error) {
-	assets, err := r.Repo.GetAssetsByType(context.Background(), "Savings")
+// StockPnL calculates the realized and unrealized stock P&L per asset in the base currency.
+func (r *ReturnsCalculator) StockPnL(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.StockValuations(ctx))
+}
+
+// StockReturns calculates the stock P&L grouped by ticker.
+func (r *ReturnsCalculator) StockReturns(ctx context.Context) (map[int]float32, error) {
+	return totals(r.StockPnL(ctx))
+}
+
+// InterestValuations values savings assets at principal plus the interest earned,
+// which stays unrealized until withdrawn.
+func (r *ReturnsCalculator) InterestValuations(ctx context.Context) (map[int]models.Valuation, error) {
+	assets, holdings, err := r.Assets.GetHoldingsByType(ctx, "Savings")
 	if err != nil {
 		return nil, err
 	}
 
-	pnlByAsset := make(map[int]float32)
+	valuations := make(map[int]models.Valuation)
 
 	for _, asset := range assets {
-		if asset.InterestRate == 0 || asset.Amount == 0 || asset.InterestStart.IsZero() {
+		if asset.InterestStart.IsZero() {
 			return nil, errors.New("missing required fields in asset")
 		}
-
-		principal := float64(asset.Amount)
-		interestRate := float64(asset.InterestRate) / 100
-		startDate := asset.InterestStart
-		now := time.Now()
-
-		if now.Before(startDate) {
+		// An account withdrawn in full holds nothing more to value
+		holding := holdings[asset.ID]
+		if holding.Quantity == 0 {
 			continue
 		}
 
-		monthsElapsed := float64(now.Year()-startDate.Year())*12 + float64(now.Month()-startDate.Month())
-
-		if monthsElapsed <= 0 {
+		interest, ok, err := r.Assets.AccruedInterest(ctx, asset, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
 			continue
 		}
-
-		var compoundingPeriods int
-		switch asset.CompoundingFrequency {
-		case "daily":
-			compoundingPeriods = 365
-		case "quarterly":
-			compoundingPeriods = 4
-		case "annually":
-			compoundingPeriods = 1
-		default:
-			compoundingPeriods = 12
+		valuations[asset.ID], err = r.Converter.valuation(asset, holding, 1, models.NewPnL(asset.ID, 0, interest))
+		if err != nil {
+			return nil, err
 		}
-
-		yearsElapsed := monthsElapsed / 12
-		finalAmount := principal * math.Pow(1+interestRate/float64(compoundingPeriods), float64(compoundingPeriods)*yearsElapsed)
-		pl := finalAmount - principal
-		pnlByAsset[asset.ID] = float32(pl)
 	}
 
-	return pnlByAsset, nil
+	return valuations, nil
+}
+
+// InterestPnL calculates the interest earned on savings assets in the base currency.
+func (r *ReturnsCalculator) InterestPnL(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.InterestValuations(ctx))
+}
+
+// CalculateInterestPL calculates the interest P&L for assets with interest-bearing properties.
+func (r *ReturnsCalculator) CalculateInterestPL(ctx context.Context) (map[int]float32, error) {
+	return totals(r.InterestPnL(ctx))
+}
+
+// GoldValuations marks every gold holding at the cached price per gram.
+func (r *ReturnsCalculator) GoldValuations(ctx context.Context) (map[int]models.Valuation, error) {
+	return r.marketValuations(ctx, "Gold", "goldPrice")
+}
+
+// GoldPnL calculates the realized and unrealized gold P&L per asset in the base currency.
+func (r *ReturnsCalculator) GoldPnL(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.GoldValuations(ctx))
+}
+
+func (r *ReturnsCalculator) GoldReturns(ctx context.Context) (map[int]float32, error) {
+	return totals(r.GoldPnL(ctx))
+}
+
+// CryptoValuations marks every crypto holding at its cached coin price.
+func (r *ReturnsCalculator) CryptoValuations(ctx context.Context) (map[int]models.Valuation, error) {
+	return r.marketValuations(ctx, "Crypto", "cryptoPrice")
+}
+
+// CryptoPnL calculates the realized and unrealized crypto P&L per asset in the base currency.
+func (r *ReturnsCalculator) CryptoPnL(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.CryptoValuations(ctx))
+}
+
+func (r *ReturnsCalculator) CryptoReturns(ctx context.Context) (map[int]float32, error) {
+	return totals(r.CryptoPnL(ctx))
 }
 
-func (r *ReturnsCalculator) GoldReturns() (map[int]float32, error) {
-	gold, err := r.Repo.GetAssetsByType(context.Background(), "Gold")
+// BondValuations values every bond at its accrual price including accrued interest,
+// with coupons already booked to the ledger counted as realized income.
+func (r *ReturnsCalculator) BondValuations(ctx context.Context) (map[int]models.Valuation, error) {
+	bonds, holdings, err := r.Assets.GetHoldingsByType(ctx, "Bond")
 	if err != nil {
 		return nil, err
 	}
-	pnlByName := make(map[int]float32)
+	valuations := make(map[int]models.Valuation)
 
-	for _, g := range gold {
-		currentPrice, ok := r.cache.Get("goldPrice")
-		if !ok {
+	for _, bond := range bonds {
+		value, err := ValueBond(bond, holdings[bond.ID], time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("bond %s: %w", bond.Name, err)
+		}
+		valuations[bond.ID], err = r.Converter.Valuate(bond, holdings[bond.ID], value.Quote(bond.Currency))
+		if err != nil {
 			return nil, err
 		}
+	}
+	return valuations, nil
+}
 
-		price, ok := currentPrice.Value.(GoldResponse)
-		if !ok {
+// BondPnL calculates the realized and unrealized bond P&L per asset in the base currency.
+func (r *ReturnsCalculator) BondPnL(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.BondValuations(ctx))
+}
+
+func (r *ReturnsCalculator) BondReturns(ctx context.Context) (map[int]float32, error) {
+	return totals(r.BondPnL(ctx))
+}
+
+// Valuations returns the valuation of every priced or interest-bearing asset.
+func (r *ReturnsCalculator) Valuations(ctx context.Context) (map[int]models.Valuation, error) {
+	valuations := make(map[int]models.Valuation)
+
+	for _, calc := range []func(context.Context) (map[int]models.Valuation, error){
+		r.StockValuations, r.InterestValuations, r.GoldValuations, r.CryptoValuations, r.BondValuations,
+	} {
+		v, err := calc(ctx)
+		if err != nil {
 			return nil, err
 		}
+		maps.Copy(valuations, v)
+	}
+
+	return valuations, nil
+}
+
+// ProfitAndLoss returns the realized and unrealized P&L of every priced or interest-bearing asset in the base currency.
+func (r *ReturnsCalculator) ProfitAndLoss(ctx context.Context) (map[int]models.PnL, error) {
+	return basePnL(r.Valuations(ctx))
+}
+
+// basePnL keeps only the base-currency P&L of each valuation.
+func basePnL(valuations map[int]models.Valuation, err error) (map[int]models.PnL, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	pnlByAsset := make(map[int]models.PnL, len(valuations))
+	for id, v := range valuations {
+		pnlByAsset[id] = v.BasePnL
+	}
+	return pnlByAsset, nil
+}
 
-		pnl := (float32(price.Price) - g.Price) * g.Amount
+// totals collapses split P&L into the single figure per asset served by GET /api/returns.
+func totals(pnl map[int]models.PnL, err error) (map[int]float32, error) {
+	if err != nil {
+		return nil, err
+	}
 
-		pnlByName[g.ID] += pnl
+	totalByAsset := make(map[int]float32, len(pnl))
+	for id, p := range pnl {
+		totalByAsset[id] = float32(p.Total)
 	}
-	return pnlByName, nil
+	return totalByAsset, nil
 }
 
-func (r *ReturnsCalculator) TotalReturns() (float32, error) {
+func (r *ReturnsCalculator) TotalReturns(ctx context.Context) (float32, error) {
 	var total float32
 
 	// Stock Returns
-	stockReturns, err := r.StockReturns()
+	stockReturns, err := r.StockReturns(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -141,7 +254,7 @@ func (r *ReturnsCalculator) TotalReturns() (float32, error) {
 	}
 
 	// Interest Returns
-	interestReturns, err := r.CalculateInterestPL()
+	interestReturns, err := r.CalculateInterestPL(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -150,7 +263,7 @@ func (r *ReturnsCalculator) TotalReturns() (float32, error) {
 	}
 
 	// Gold Returns
-	goldReturns, err := r.GoldReturns()
+	goldReturns, err := r.GoldReturns(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -158,9 +271,23 @@ func (r *ReturnsCalculator) TotalReturns() (float32, error) {
 		total += pnl
 	}
 
-	return total, nil
-}
+	// Crypto Returns
+	cryptoReturns, err := r.CryptoReturns(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, pnl := range cryptoReturns {
+		total += pnl
+	}
+
+	// Bond Returns
+	bondReturns, err := r.BondReturns(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for _, pnl := range bondReturns {
+		total += pnl
+	}
 
-func (r *ReturnsCalculator) GetMonthlyReturns() ([]repositories.MonthlyReturn, error) {
-	return r.HistRepo.GetMonthlyReturns(context.Background())
+	return total, nil
 }
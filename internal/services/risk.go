@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// RiskService measures the volatility of the portfolio's daily returns.
+type RiskService struct {
+	performance  *PerformanceService
+	priceRepo    *repositories.PriceRepository
+	prices       *PriceRegistry
+	RiskFreeRate float64
+}
+
+func NewRiskService(performance *PerformanceService,
+	priceRepo *repositories.PriceRepository,
+	prices *PriceRegistry,
+	riskFreeRate float64) *RiskService {
+	return &RiskService{performance: performance, priceRepo: priceRepo, prices: prices, RiskFreeRate: riskFreeRate}
+}
+
+// Risk reports annualized volatility, maximum drawdown, Sharpe and Sortino ratios and,
+// when a benchmark ticker is given, the portfolio's beta against it between from and to.
+// riskFreeRate is an annual rate such as 0.03. Returns are annualized by how often
+// asset_returns was recorded over the period, so gaps in the history do not skew them.
+func (s *RiskService) Risk(ctx context.Context, from, to time.Time, riskFreeRate float64, benchmark string) (models.RiskReport, error) {
+	if benchmark != "" {
+		if err := validateTicker(benchmark); err != nil {
+			return models.RiskReport{}, err
+		}
+	}
+
+	points, err := s.performance.PortfolioSeries(ctx, from, to)
+	if err != nil {
+		return models.RiskReport{}, err
+	}
+
+	report := models.RiskReport{
+		From:         from,
+		To:           to,
+		BaseCurrency: s.performance.converter.Base,
+		RiskFreeRate: riskFreeRate,
+		Benchmark:    benchmark,
+	}
+
+	returns := periodReturns(points)
+	report.Observations = len(returns)
+	if len(returns) < 2 {
+		return report, nil
+	}
+	report.MaxDrawdown = maxDrawdown(points[0].Date, returns)
+
+	years := points[len(points)-1].Date.Sub(points[0].Date).Hours() / 24 / 365
+	perYear := float64(len(returns)) / years
+
+	values := make([]float64, len(returns))
+	for i, r := range returns {
+		values[i] = r.Return
+	}
+	mean, stdev := meanStdev(values)
+	report.Return = mean * perYear
+	report.Volatility = stdev * math.Sqrt(perYear)
+
+	if report.Volatility > 0 {
+		sharpe := (report.Return - riskFreeRate) / report.Volatility
+		report.Sharpe = &sharpe
+	}
+	if downside := downsideDeviation(values, riskFreeRate/perYear) * math.Sqrt(perYear); downside > 0 {
+		sortino := (report.Return - riskFreeRate) / downside
+		report.Sortino = &sortino
+	}
+
+	if benchmark != "" {
+		closes, err := dailyCloses(ctx, s.priceRepo, s.prices, "Stock", benchmark, s.performance.converter.Base, points[0].Date, to)
+		if err != nil {
+			return models.RiskReport{}, err
+		}
+		b, periods, ok := beta(returns, points, closes)
+		if ok {
+			report.Beta = &b
+		}
+		report.BetaObservations = periods
+	}
+
+	return report, nil
+}
+
+// maxDrawdown finds the deepest fall of the growth of a series of period returns from its
+// running high. start is the date of the value the first return is measured from.
+func maxDrawdown(start time.Time, returns []periodReturn) models.Drawdown {
+	var worst models.Drawdown
+	growth, high := 1.0, 1.0
+	highDate := start
+
+	for _, r := range returns {
+		growth *= 1 + r.Return
+		if growth >= high {
+			if worst.Depth > 0 && worst.Recovered == nil && worst.Peak.Equal(highDate) {
+				recovered := r.Date
+				worst.Recovered = &recovered
+			}
+			high, highDate = growth, r.Date
+			continue
+		}
+		if depth := 1 - growth/high; depth > worst.Depth {
+			worst = models.Drawdown{Depth: depth, Peak: highDate, Trough: r.Date}
+		}
+	}
+	return worst
+}
+
+// beta is the covariance of the portfolio's period returns with the benchmark's returns
+// over the same periods, divided by the variance of the benchmark's returns. It also returns
+// the number of periods the benchmark had closes for.
+func beta(returns []periodReturn, points []models.ValuePoint, closes []models.Quote) (float64, int, bool) {
+	var portfolio, market []float64
+	for _, r := range returns {
+		i := slices.IndexFunc(points, func(p models.ValuePoint) bool { return p.Date.Equal(r.Date) })
+		if i < 1 {
+			continue
+		}
+		previous, ok := closeOn(closes, points[i-1].Date)
+		if !ok || previous <= 0 {
+			continue
+		}
+		current, _ := closeOn(closes, r.Date)
+		portfolio = append(portfolio, r.Return)
+		market = append(market, current/previous-1)
+	}
+	if len(market) < 2 {
+		return 0, len(market), false
+	}
+
+	meanP, _ := meanStdev(portfolio)
+	meanM, _ := meanStdev(market)
+	var covariance, variance float64
+	for i := range market {
+		covariance += (portfolio[i] - meanP) * (market[i] - meanM)
+		variance += (market[i] - meanM) * (market[i] - meanM)
+	}
+	if variance == 0 {
+		return 0, len(market), false
+	}
+	return covariance / variance, len(market), true
+}
+
+// closeOn returns the last of the daily closes, oldest first, on or before date.
+func closeOn(closes []models.Quote, date time.Time) (float64, bool) {
+	i, found := slices.BinarySearchFunc(closes, date, func(q models.Quote, t time.Time) int {
+		return day(q.Timestamp).Compare(t)
+	})
+	if found {
+		return closes[i].Price, true
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return closes[i-1].Price, true
+}
+
+// meanStdev returns the mean and sample standard deviation of values.
+func meanStdev(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(squares / float64(len(values)-1))
+}
+
+// downsideDeviation is the root mean square of the shortfalls of values below target.
+func downsideDeviation(values []float64, target float64) float64 {
+	var squares float64
+	for _, v := range values {
+		if v < target {
+			squares += (v - target) * (v - target)
+		}
+	}
+	return math.Sqrt(squares / float64(len(values)))
+}
+
+// dailyCloses returns the persisted daily closes of a symbol between from and to. When the
+// stored closes start later than from, e.g. because the hourly job only began storing the
+// symbol recently, the history before them is fetched from the price providers and
+// persisted so the next request is served from the database.
+func dailyCloses(ctx context.Context, priceRepo *repositories.PriceRepository, prices *PriceRegistry,
+	assetType, symbol, currency string, from, to time.Time) ([]models.Quote, error) {
+	closes, err := priceRepo.GetDailyCloses(ctx, assetType, symbol, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// Weekends and holidays leave the first days of a range without a close
+	if len(closes) > 0 && !day(closes[0].Timestamp).After(day(from).AddDate(0, 0, 4)) {
+		return closes, nil
+	}
+
+	until := to
+	if len(closes) > 0 {
+		until = closes[0].Timestamp
+	}
+	history, err := prices.History(ctx, assetType, symbol, currency, from, until)
+	if err != nil {
+		return nil, err
+	}
+	for _, quote := range history {
+		if err := priceRepo.InsertQuote(ctx, assetType, quote); err != nil {
+			return nil, err
+		}
+	}
+	return priceRepo.GetDailyCloses(ctx, assetType, symbol, from, to)
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// series turns daily values, starting on start, into a value series without flows.
+func series(start time.Time, values ...float64) []models.ValuePoint {
+	points := make([]models.ValuePoint, len(values))
+	for i, v := range values {
+		points[i] = models.ValuePoint{Date: start.AddDate(0, 0, i), Value: v}
+	}
+	return points
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	on := func(days int) time.Time { return start.AddDate(0, 0, days) }
+
+	tests := []struct {
+		name   string
+		values []float64
+		want   models.Drawdown
+	}{
+		{
+			name:   "only rising",
+			values: []float64{100, 110, 120},
+			want:   models.Drawdown{},
+		},
+		{
+			name:   "recovered",
+			values: []float64{100, 120, 90, 110, 130},
+			want:   models.Drawdown{Depth: 0.25, Peak: on(1), Trough: on(2), Recovered: ptrTime(on(4))},
+		},
+		{
+			name:   "not yet recovered",
+			values: []float64{100, 80, 90},
+			want:   models.Drawdown{Depth: 0.2, Peak: on(0), Trough: on(1)},
+		},
+		{
+			name:   "the deeper of two",
+			values: []float64{100, 90, 110, 77, 120},
+			want:   models.Drawdown{Depth: 0.3, Peak: on(2), Trough: on(3), Recovered: ptrTime(on(4))},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := maxDrawdown(start, periodReturns(series(start, tt.values...)))
+
+			if !near(got.Depth, tt.want.Depth) || !got.Peak.Equal(tt.want.Peak) || !got.Trough.Equal(tt.want.Trough) {
+				t.Errorf("maxDrawdown() = %.4f from %s to %s, want %.4f from %s to %s", got.Depth,
+					got.Peak.Format(time.DateOnly), got.Trough.Format(time.DateOnly),
+					tt.want.Depth, tt.want.Peak.Format(time.DateOnly), tt.want.Trough.Format(time.DateOnly))
+			}
+			switch {
+			case tt.want.Recovered == nil && got.Recovered != nil:
+				t.Errorf("recovered on %s, want not recovered", got.Recovered.Format(time.DateOnly))
+			case tt.want.Recovered != nil && (got.Recovered == nil || !got.Recovered.Equal(*tt.want.Recovered)):
+				t.Errorf("recovered on %v, want %s", got.Recovered, tt.want.Recovered.Format(time.DateOnly))
+			}
+		})
+	}
+}
+
+func TestBeta(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	closes := func(prices ...float64) []models.Quote {
+		quotes := make([]models.Quote, len(prices))
+		for i, p := range prices {
+			quotes[i] = models.Quote{Price: p, Timestamp: start.AddDate(0, 0, i)}
+		}
+		return quotes
+	}
+
+	tests := []struct {
+		name        string
+		values      []float64
+		closes      []models.Quote
+		want        float64
+		wantPeriods int
+		wantOK      bool
+	}{
+		{
+			name:        "moves twice as much as the market",
+			values:      []float64{100, 120, 96, 115.2},
+			closes:      closes(100, 110, 99, 108.9),
+			want:        2,
+			wantOK:      true,
+			wantPeriods: 3,
+		},
+		{
+			name:        "moves against the market",
+			values:      []float64{100, 95, 99.75, 94.7625},
+			closes:      closes(100, 110, 99, 108.9),
+			want:        -0.5,
+			wantOK:      true,
+			wantPeriods: 3,
+		},
+		{
+			name:        "periods before the first close are skipped",
+			values:      []float64{100, 120, 96, 115.2},
+			closes:      closes(100, 110, 99, 108.9)[1:],
+			want:        2,
+			wantOK:      true,
+			wantPeriods: 2,
+		},
+		{
+			name:        "a flat market has no beta",
+			values:      []float64{100, 120, 96, 115.2},
+			closes:      closes(100, 100, 100, 100),
+			wantPeriods: 3,
+		},
+		{
+			name:        "one period is not enough",
+			values:      []float64{100, 120},
+			closes:      closes(100, 110),
+			wantPeriods: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			points := series(start, tt.values...)
+			got, periods, ok := beta(periodReturns(points), points, tt.closes)
+			if ok != tt.wantOK || !near(got, tt.want) || periods != tt.wantPeriods {
+				t.Errorf("beta() = %v over %d periods, %v, want %v over %d, %v", got, periods, ok,
+					tt.want, tt.wantPeriods, tt.wantOK)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -1,48 +1,164 @@
 package services
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"time"
+
+	"github.com/jagac/pfinance/internal/models"
 )
 
-type StockFetcher struct{}
+// StockFetcher fetches stock prices from the stockapi service.
+type StockFetcher struct {
+	BaseURL string
+}
 
-func NewStockFetcher() *StockFetcher {
-	return &StockFetcher{}
+func NewStockFetcher(baseURL string) *StockFetcher {
+	return &StockFetcher{BaseURL: baseURL}
 }
 
 type StockResponse struct {
 	Symbol    string    `json:"symbol"`
-	Price     float32   `json:"price"`
+	Price     float64   `json:"price"`
+	Currency  string    `json:"currency"`
 	Timestamp time.Time `json:"timestamp"`
 }
 
-func (s *StockFetcher) FetchPrice(ticker string) (StockResponse, error) {
-	reqUrl := fmt.Sprintf("http://stockapi:4000/stock/%s", ticker)
+func (s *StockFetcher) Name() string {
+	return "stockapi"
+}
+
+// Quote fetches the latest price of ticker in its listing currency.
+func (s *StockFetcher) Quote(ctx context.Context, ticker, _ string) (models.Quote, error) {
+	reqUrl := fmt.Sprintf("%s/stock/%s", s.BaseURL, url.PathEscape(ticker))
 
 	client := &http.Client{}
 
-	req, err := http.NewRequest("GET", reqUrl, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
 	if err != nil {
-		return StockResponse{}, fmt.Errorf("failed to create request: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to create request: %v", err)
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return StockResponse{}, fmt.Errorf("failed to send request: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to send request: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return StockResponse{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return models.Quote{}, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
 	var stockResponse StockResponse
 	if err := json.NewDecoder(resp.Body).Decode(&stockResponse); err != nil {
-		return StockResponse{}, fmt.Errorf("failed to decode response: %v", err)
+		return models.Quote{}, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	// London listings are quoted in pence
+	if stockResponse.Currency == "GBp" {
+		stockResponse.Price /= 100
+		stockResponse.Currency = "GBP"
+	}
+
+	return models.Quote{
+		Symbol:    ticker,
+		Price:     stockResponse.Price,
+		Currency:  stockResponse.Currency,
+		Timestamp: stockResponse.Timestamp,
+	}, nil
+}
+
+// History fetches the daily closes of ticker between from and to in its listing currency.
+func (s *StockFetcher) History(ctx context.Context, ticker, _ string, from, to time.Time) ([]models.Quote, error) {
+	reqUrl := fmt.Sprintf("%s/stock/%s/history?from=%s&to=%s",
+		s.BaseURL, url.PathEscape(ticker), from.Format(time.DateOnly), to.AddDate(0, 0, 1).Format(time.DateOnly))
+
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var history struct {
+		Currency string `json:"currency"`
+		Prices   []struct {
+			Date  time.Time `json:"date"`
+			Close float64   `json:"close"`
+		} `json:"prices"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	quotes := make([]models.Quote, 0, len(history.Prices))
+	for _, price := range history.Prices {
+		quote := models.Quote{Symbol: ticker, Price: price.Close, Currency: history.Currency, Timestamp: day(price.Date)}
+		// London listings are quoted in pence
+		if quote.Currency == "GBp" {
+			quote.Price /= 100
+			quote.Currency = "GBP"
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
+
+// Dividends fetches the dividends per share ticker went ex between from and to in its listing currency.
+func (s *StockFetcher) Dividends(ctx context.Context, ticker string, from, to time.Time) ([]models.Dividend, error) {
+	reqUrl := fmt.Sprintf("%s/stock/%s/dividends?from=%s&to=%s",
+		s.BaseURL, url.PathEscape(ticker), from.Format(time.DateOnly), to.AddDate(0, 0, 1).Format(time.DateOnly))
+
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
-	return stockResponse, nil
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var response struct {
+		Currency  string `json:"currency"`
+		Dividends []struct {
+			Date   time.Time `json:"date"`
+			Amount float64   `json:"amount"`
+		} `json:"dividends"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	dividends := make([]models.Dividend, 0, len(response.Dividends))
+	for _, d := range response.Dividends {
+		dividend := models.Dividend{Symbol: ticker, ExDate: day(d.Date), Amount: d.Amount, Currency: response.Currency}
+		// London listings pay in pence
+		if dividend.Currency == "GBp" {
+			dividend.Amount /= 100
+			dividend.Currency = "GBP"
+		}
+		dividends = append(dividends, dividend)
+	}
+	return dividends, nil
 }
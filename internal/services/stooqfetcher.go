@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// stooqMarkets maps Yahoo style ticker suffixes to the stooq market suffix and
+// the currency the market quotes in. Tickers without a suffix are US listings.
+var stooqMarkets = map[string]struct{ suffix, currency string }{
+	"":   {"us", "USD"},
+	"L":  {"uk", "GBp"},
+	"DE": {"de", "EUR"},
+	"F":  {"de", "EUR"},
+	"PA": {"fr", "EUR"},
+	"T":  {"jp", "JPY"},
+	"HK": {"hk", "HKD"},
+}
+
+// StooqStockFetcher is a fallback stock price source reading stooq.com's CSV quotes.
+type StooqStockFetcher struct{}
+
+func NewStooqStockFetcher() *StooqStockFetcher {
+	return &StooqStockFetcher{}
+}
+
+func (s *StooqStockFetcher) Name() string {
+	return "stooq"
+}
+
+func (s *StooqStockFetcher) Quote(ctx context.Context, ticker, _ string) (models.Quote, error) {
+	symbol, suffix, _ := strings.Cut(ticker, ".")
+	market, ok := stooqMarkets[strings.ToUpper(suffix)]
+	if !ok {
+		return models.Quote{}, fmt.Errorf("market of %s is not supported", ticker)
+	}
+
+	price, err := fetchStooqClose(ctx, strings.ToLower(symbol)+"."+market.suffix)
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	quote := models.Quote{Symbol: ticker, Price: price, Currency: market.currency, Timestamp: time.Now()}
+	if quote.Currency == "GBp" {
+		quote.Price /= 100
+		quote.Currency = "GBP"
+	}
+	return quote, nil
+}
+
+// History fetches the daily closes of ticker between from and to.
+func (s *StooqStockFetcher) History(ctx context.Context, ticker, _ string, from, to time.Time) ([]models.Quote, error) {
+	symbol, suffix, _ := strings.Cut(ticker, ".")
+	market, ok := stooqMarkets[strings.ToUpper(suffix)]
+	if !ok {
+		return nil, fmt.Errorf("market of %s is not supported", ticker)
+	}
+
+	closes, err := fetchStooqHistory(ctx, strings.ToLower(symbol)+"."+market.suffix, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]models.Quote, 0, len(closes))
+	for _, c := range closes {
+		quote := models.Quote{Symbol: ticker, Price: c.price, Currency: market.currency, Timestamp: c.date}
+		if quote.Currency == "GBp" {
+			quote.Price /= 100
+			quote.Currency = "GBP"
+		}
+		quotes = append(quotes, quote)
+	}
+	return quotes, nil
+}
+
+// StooqGoldFetcher is a fallback gold price source using stooq's XAU currency pairs.
+type StooqGoldFetcher struct{}
+
+func NewStooqGoldFetcher() *StooqGoldFetcher {
+	return &StooqGoldFetcher{}
+}
+
+func (s *StooqGoldFetcher) Name() string {
+	return "stooq"
+}
+
+func (s *StooqGoldFetcher) Quote(ctx context.Context, _, currency string) (models.Quote, error) {
+	pricePerOunce, err := fetchStooqClose(ctx, "xau"+strings.ToLower(currency))
+	if err != nil {
+		return models.Quote{}, err
+	}
+
+	return models.Quote{
+		Symbol:    GoldSymbol,
+		Price:     pricePerOunce / gramsPerTroyOunce,
+		Currency:  currency,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// History fetches the daily gold closes per gram between from and to.
+func (s *StooqGoldFetcher) History(ctx context.Context, _, currency string, from, to time.Time) ([]models.Quote, error) {
+	closes, err := fetchStooqHistory(ctx, "xau"+strings.ToLower(currency), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := make([]models.Quote, 0, len(closes))
+	for _, c := range closes {
+		quotes = append(quotes, models.Quote{
+			Symbol:    GoldSymbol,
+			Price:     c.price / gramsPerTroyOunce,
+			Currency:  currency,
+			Timestamp: c.date,
+		})
+	}
+	return quotes, nil
+}
+
+type stooqClose struct {
+	date  time.Time
+	price float64
+}
+
+// fetchStooqHistory returns the daily closes of a stooq symbol between from and to.
+func fetchStooqHistory(ctx context.Context, symbol string, from, to time.Time) ([]stooqClose, error) {
+	reqUrl := fmt.Sprintf("https://stooq.com/q/d/l/?s=%s&d1=%s&d2=%s&i=d",
+		url.QueryEscape(symbol), from.Format("20060102"), to.Format("20060102"))
+
+	records, err := fetchStooqCSV(ctx, reqUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header followed by Date,Open,High,Low,Close,Volume
+	var closes []stooqClose
+	for _, record := range records[1:] {
+		if len(record) < 5 {
+			continue
+		}
+		date, err := time.Parse(time.DateOnly, record[0])
+		if err != nil {
+			continue
+		}
+		price, err := strconv.ParseFloat(record[4], 64)
+		if err != nil {
+			continue
+		}
+		closes = append(closes, stooqClose{date: date, price: price})
+	}
+
+	if len(closes) == 0 {
+		return nil, fmt.Errorf("no price history found for %s", symbol)
+	}
+	return closes, nil
+}
+
+// fetchStooqClose returns the latest close of a stooq symbol.
+func fetchStooqClose(ctx context.Context, symbol string) (float64, error) {
+	reqUrl := fmt.Sprintf("https://stooq.com/q/l/?s=%s&f=sd2t2ohlcv&h&e=csv", url.QueryEscape(symbol))
+
+	records, err := fetchStooqCSV(ctx, reqUrl)
+	if err != nil {
+		return 0, err
+	}
+	// Header followed by Symbol,Date,Time,Open,High,Low,Close,Volume
+	if len(records) < 2 || len(records[1]) < 7 {
+		return 0, fmt.Errorf("no price data found for %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(records[1][6], 64)
+	if err != nil {
+		return 0, fmt.Errorf("no price data found for %s", symbol)
+	}
+	return price, nil
+}
+
+func fetchStooqCSV(ctx context.Context, reqUrl string) ([][]string, error) {
+	client := &http.Client{}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", reqUrl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	reader := csv.NewReader(resp.Body)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty response")
+	}
+	return records, nil
+}
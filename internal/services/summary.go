@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+// Summary returns the market value, cost basis, P&L and weight of every asset, aggregated
+// by asset type and by currency, all in the base currency.
+func (r *ReturnsCalculator) Summary(ctx context.Context) (models.PortfolioSummary, error) {
+	assets, err := r.Repo.GetAllAssets(ctx)
+	if err != nil {
+		return models.PortfolioSummary{}, err
+	}
+	valuations, err := r.Valuations(ctx)
+	if err != nil {
+		return models.PortfolioSummary{}, err
+	}
+
+	summary := models.PortfolioSummary{
+		BaseCurrency: r.Converter.Base,
+		Assets:       []models.AssetSummary{},
+		ByType:       make(map[string]models.AllocationSummary),
+		ByCurrency:   make(map[string]models.AllocationSummary),
+	}
+
+	add := func(total models.AllocationSummary, line models.AssetSummary) models.AllocationSummary {
+		total.CostBasis += line.CostBasis
+		total.MarketValue += line.MarketValue
+		total.PnL = models.NewPnL(0, total.PnL.Realized+line.PnL.Realized, total.PnL.Unrealized+line.PnL.Unrealized)
+		return total
+	}
+
+	var portfolio models.AllocationSummary
+	for _, asset := range assets {
+		v, ok := valuations[asset.ID]
+		if !ok {
+			continue
+		}
+		line := models.AssetSummary{
+			AssetID:     asset.ID,
+			Name:        asset.Name,
+			Type:        asset.Type,
+			Currency:    v.Currency,
+			Quantity:    v.Quantity,
+			Price:       v.Price,
+			PriceSource: v.PriceSource,
+			CostBasis:   v.BaseCostBasis,
+			MarketValue: v.BaseMarketValue,
+			PnL:         v.BasePnL,
+		}
+		summary.Assets = append(summary.Assets, line)
+		summary.ByType[asset.Type] = add(summary.ByType[asset.Type], line)
+		summary.ByCurrency[line.Currency] = add(summary.ByCurrency[line.Currency], line)
+		portfolio = add(portfolio, line)
+	}
+
+	summary.CostBasis = portfolio.CostBasis
+	summary.MarketValue = portfolio.MarketValue
+	summary.PnL = portfolio.PnL
+	if summary.MarketValue == 0 {
+		return summary, nil
+	}
+
+	weight := func(value float64) float64 { return value / summary.MarketValue * 100 }
+	for i := range summary.Assets {
+		summary.Assets[i].Weight = weight(summary.Assets[i].MarketValue)
+	}
+	for key, total := range summary.ByType {
+		total.Weight = weight(total.MarketValue)
+		summary.ByType[key] = total
+	}
+	for key, total := range summary.ByCurrency {
+		total.Weight = weight(total.MarketValue)
+		summary.ByCurrency[key] = total
+	}
+	return summary, nil
+}
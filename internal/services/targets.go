@@ -0,0 +1,161 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/jagac/pfinance/internal/models"
+	"github.com/jagac/pfinance/internal/repositories"
+)
+
+// TargetService manages target allocations and suggests the trades that restore them.
+type TargetService struct {
+	Repo      *repositories.TargetRepository
+	assetRepo *repositories.AssetRepository
+	returns   *ReturnsCalculator
+}
+
+func NewTargetService(repo *repositories.TargetRepository, assetRepo *repositories.AssetRepository, returns *ReturnsCalculator) *TargetService {
+	return &TargetService{Repo: repo, assetRepo: assetRepo, returns: returns}
+}
+
+func (s *TargetService) CreateTarget(ctx context.Context, target *models.Target) error {
+	if err := s.validate(ctx, target); err != nil {
+		return err
+	}
+	return s.Repo.AddTarget(ctx, target)
+}
+
+func (s *TargetService) UpdateTarget(ctx context.Context, target *models.Target) error {
+	if err := s.validate(ctx, target); err != nil {
+		return err
+	}
+	return s.Repo.UpdateTarget(ctx, target)
+}
+
+func (s *TargetService) DeleteTarget(ctx context.Context, id int) error {
+	return s.Repo.DeleteTarget(ctx, id)
+}
+
+// validate checks that a target names either an asset type or an asset and that the
+// targets together do not ask for more than the whole portfolio.
+func (s *TargetService) validate(ctx context.Context, target *models.Target) error {
+	if (target.AssetType == "") == (target.AssetID == nil) {
+		return errors.New("a target needs either an asset type or an asset ID")
+	}
+	if target.Percent <= 0 || target.Percent > 100 {
+		return errors.New("target percent must be between 0 and 100")
+	}
+	if target.AssetID != nil {
+		if _, err := s.assetRepo.GetAssetByID(ctx, *target.AssetID); err != nil {
+			return fmt.Errorf("asset %d: %w", *target.AssetID, err)
+		}
+	}
+
+	targets, err := s.Repo.GetAllTargets(ctx)
+	if err != nil {
+		return err
+	}
+	total := target.Percent
+	for _, other := range targets {
+		if other.ID != target.ID {
+			total += other.Percent
+		}
+	}
+	if total > 100 {
+		return fmt.Errorf("targets would add up to %.2f%%", total)
+	}
+	return nil
+}
+
+// Rebalance compares the market value behind every target with its share of the portfolio
+// plus cash, the new money to invest. Targets that drifted less than threshold percentage
+// points are left alone. With noSell only underweight targets are bought, with no more
+// than the new cash, split in proportion to how far each one is short.
+func (s *TargetService) Rebalance(ctx context.Context, cash, threshold float64, noSell bool) (models.RebalancePlan, error) {
+	targets, err := s.Repo.GetAllTargets(ctx)
+	if err != nil {
+		return models.RebalancePlan{}, err
+	}
+	assets, err := s.assetRepo.GetAllAssets(ctx)
+	if err != nil {
+		return models.RebalancePlan{}, err
+	}
+	valuations, err := s.returns.Valuations(ctx)
+	if err != nil {
+		return models.RebalancePlan{}, err
+	}
+
+	return rebalance(models.RebalancePlan{
+		BaseCurrency: s.returns.Converter.Base,
+		Cash:         cash,
+		Threshold:    threshold,
+		NoSell:       noSell,
+		Trades:       []models.RebalanceTrade{},
+	}, targets, assets, valuations), nil
+}
+
+// rebalance fills in plan, which carries the cash, threshold and noSell settings, with the
+// trades that bring assets, valued in the base currency, back to targets.
+func rebalance(plan models.RebalancePlan, targets []*models.Target, assets []*models.Asset, valuations map[int]models.Valuation) models.RebalancePlan {
+	cash, threshold, noSell := plan.Cash, plan.Threshold, plan.NoSell
+	targeted := make(map[int]bool)
+	for _, target := range targets {
+		if target.AssetID != nil {
+			targeted[*target.AssetID] = true
+		}
+	}
+	byType := make(map[string]float64)
+	for _, asset := range assets {
+		value := valuations[asset.ID].BaseMarketValue
+		plan.MarketValue += value
+		if !targeted[asset.ID] {
+			byType[asset.Type] += value
+		}
+	}
+
+	after := plan.MarketValue + cash
+	var buys float64
+	for _, target := range targets {
+		trade := models.RebalanceTrade{
+			TargetID:      target.ID,
+			AssetType:     target.AssetType,
+			AssetID:       target.AssetID,
+			TargetPercent: target.Percent,
+			MarketValue:   byType[target.AssetType],
+			TargetValue:   target.Percent / 100 * after,
+		}
+		if target.AssetID != nil {
+			trade.MarketValue = valuations[*target.AssetID].BaseMarketValue
+		}
+		if plan.MarketValue > 0 {
+			trade.CurrentPercent = trade.MarketValue / plan.MarketValue * 100
+		}
+		trade.Drift = trade.CurrentPercent - trade.TargetPercent
+
+		if math.Abs(trade.Drift) >= threshold {
+			trade.Amount = trade.TargetValue - trade.MarketValue
+			if noSell {
+				trade.Amount = max(trade.Amount, 0)
+			}
+		}
+		buys += max(trade.Amount, 0)
+		plan.Trades = append(plan.Trades, trade)
+	}
+
+	if noSell && buys > cash {
+		for i := range plan.Trades {
+			if buys > 0 {
+				plan.Trades[i].Amount *= cash / buys
+			}
+		}
+	}
+
+	plan.Unallocated = cash
+	for _, trade := range plan.Trades {
+		plan.Unallocated -= trade.Amount
+	}
+	return plan
+}
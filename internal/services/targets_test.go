@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/jagac/pfinance/internal/models"
+)
+
+func TestRebalance(t *testing.T) {
+	stock := 3
+	assets := []*models.Asset{{ID: 1, Type: "Stock"}, {ID: 2, Type: "Bond"}, {ID: 3, Type: "Stock"}}
+	valuations := map[int]models.Valuation{
+		1: {BaseMarketValue: 580},
+		2: {BaseMarketValue: 300},
+		3: {BaseMarketValue: 120},
+	}
+	targets := []*models.Target{
+		{ID: 1, AssetType: "Stock", Percent: 50},
+		{ID: 2, AssetType: "Bond", Percent: 40},
+		{ID: 3, AssetID: &stock, Percent: 10},
+	}
+
+	tests := []struct {
+		name            string
+		cash            float64
+		threshold       float64
+		noSell          bool
+		wantAmounts     []float64
+		wantUnallocated float64
+	}{
+		{
+			name:        "sells the overweight to buy the underweight",
+			wantAmounts: []float64{-80, 100, -20},
+		},
+		{
+			name:            "leaves targets within the threshold alone",
+			threshold:       5,
+			wantAmounts:     []float64{-80, 100, 0},
+			wantUnallocated: -20,
+		},
+		{
+			name:            "no trades when nothing drifted past the threshold",
+			cash:            50,
+			threshold:       15,
+			wantAmounts:     []float64{0, 0, 0},
+			wantUnallocated: 50,
+		},
+		{
+			name:            "invests new cash across the targets",
+			cash:            1000,
+			wantAmounts:     []float64{420, 500, 80},
+			wantUnallocated: 0,
+		},
+		{
+			name:        "without selling only buys, scaled down to the cash",
+			cash:        100,
+			noSell:      true,
+			wantAmounts: []float64{0, 100, 0},
+		},
+		{
+			name:            "without selling and within the threshold keeps the cash",
+			cash:            100,
+			threshold:       15,
+			noSell:          true,
+			wantAmounts:     []float64{0, 0, 0},
+			wantUnallocated: 100,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := rebalance(models.RebalancePlan{Cash: tt.cash, Threshold: tt.threshold, NoSell: tt.noSell},
+				targets, assets, valuations)
+
+			if len(plan.Trades) != len(tt.wantAmounts) {
+				t.Fatalf("got %d trades, want %d", len(plan.Trades), len(tt.wantAmounts))
+			}
+			for i, trade := range plan.Trades {
+				if !near(trade.Amount, tt.wantAmounts[i]) {
+					t.Errorf("target %d: amount = %.4f, want %.4f", trade.TargetID, trade.Amount, tt.wantAmounts[i])
+				}
+			}
+			if !near(plan.Unallocated, tt.wantUnallocated) {
+				t.Errorf("unallocated = %.4f, want %.4f", plan.Unallocated, tt.wantUnallocated)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+import LoginForm from "@/components/Form/LoginForm";
+
+export default function LoginPage() {
+  return (
+    <main className="p-4 h-screen">
+      <LoginForm />
+    </main>
+  );
+}
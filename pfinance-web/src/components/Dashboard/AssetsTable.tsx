@@ -3,6 +3,7 @@
 import Link from "next/link";
 import React, { useEffect, useState } from "react";
 import {  FiDollarSign, FiMoreHorizontal } from "react-icons/fi";
+import { apiFetch } from "@/lib/api";
 
 interface Asset {
   id: number;
@@ -25,9 +26,10 @@ export const AssetsTable = () => {
   useEffect(() => {
     const fetchAssets = async () => {
       try {
-        const res = await fetch("https://pfinanceapi.jagactechlab.com/api/assets/all");
+        const res = await apiFetch("/api/assets/all");
+        if (!res.ok) return;
         const data: Asset[] = await res.json();
-        const retRes = await fetch(`https://pfinanceapi.jagactechlab.com/api/returns`);
+        const retRes = await apiFetch("/api/returns");
         const retData = await retRes.json();
         const assetsWithReturns = data.map(asset => ({
           ...asset,
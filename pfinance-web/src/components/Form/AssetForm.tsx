@@ -4,6 +4,7 @@ import { useState } from "react";
 import { FiDollarSign } from "react-icons/fi";
 import SelectField from "./Select";
 import InputField from "./Input";
+import { apiFetch } from "@/lib/api";
 
 export default function AddAssetForm() {
   const [form, setForm] = useState<Asset>({
@@ -39,14 +40,11 @@ export default function AddAssetForm() {
         : undefined,
     };
 
-    const response = await fetch(
-      "https://pfinanceapi.jagactechlab.com/api/assets/new",
-      {
-        method: "POST",
-        headers: { "Content-Type": "application/json" },
-        body: JSON.stringify(dataToSend),
-      }
-    );
+    const response = await apiFetch("/api/assets/new", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(dataToSend),
+    });
 
     if (response.ok) {
       alert("Asset added successfully!");
@@ -0,0 +1,68 @@
+"use client";
+import { useRouter } from "next/navigation";
+import { useState } from "react";
+import { FiLogIn } from "react-icons/fi";
+import InputField from "./Input";
+import { apiFetch } from "@/lib/api";
+
+export default function LoginForm() {
+  const router = useRouter();
+  const [form, setForm] = useState({ email: "", password: "" });
+  const [error, setError] = useState("");
+
+  const handleChange = (e: React.ChangeEvent<HTMLInputElement>) => {
+    setForm({ ...form, [e.target.name]: e.target.value });
+  };
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    const response = await apiFetch("/api/auth/login", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify(form),
+    });
+
+    if (response.ok) {
+      router.push("/");
+    } else {
+      setError("Wrong email or password.");
+    }
+  };
+
+  return (
+    <section className="relative bg-white rounded-lg">
+      <div className="container mx-auto px-6 sm:px-8">
+        <div className="mt-12 p-8 rounded-2xl max-w-md mx-auto rounded border border-stone-300">
+          <form onSubmit={handleSubmit}>
+            <InputField
+              label="Email"
+              name="email"
+              type="email"
+              value={form.email}
+              onChange={handleChange}
+              placeholder="Enter your email"
+              required
+            />
+            <InputField
+              label="Password"
+              name="password"
+              type="password"
+              value={form.password}
+              onChange={handleChange}
+              placeholder="Enter your password"
+              required
+            />
+            {error && <p className="mb-4 text-sm text-red-600">{error}</p>}
+            <button
+              type="submit"
+              className="mt-4 w-full flex items-center justify-center gap-2 p-2 bg-violet-600 text-white rounded hover:bg-violet-700 transition"
+            >
+              <FiLogIn /> Log In
+            </button>
+          </form>
+        </div>
+      </div>
+    </section>
+  );
+}
@@ -0,0 +1,15 @@
+export const API_URL =
+  process.env.NEXT_PUBLIC_API_URL ?? "https://pfinanceapi.jagactechlab.com";
+
+// apiFetch calls the API with the session cookie set at login and sends the
+// browser to the login page once the session has expired.
+export async function apiFetch(path: string, init: RequestInit = {}) {
+  const res = await fetch(`${API_URL}${path}`, {
+    ...init,
+    credentials: "include",
+  });
+  if (res.status === 401 && window.location.pathname !== "/login") {
+    window.location.href = "/login";
+  }
+  return res;
+}
@@ -28,6 +28,18 @@ type GlobalConfig struct {
 	MatrixUser        string
 	MatrixPassword    string
 	MatrixAccessToken string
+	LotMethod         string
+	BaseCurrency      string
+	StockAPIURL       string
+	RiskFreeRate      string
+	FetchDividends    string
+	SessionTTL        string
+	CORSOrigins       string
+	CORSMethods       string
+	CORSHeaders       string
+	CORSCredentials   string
+	CORSMaxAge        string
+	Notifier          string
 }
 
 var (
@@ -54,6 +66,18 @@ func LoadConfig() GlobalConfig {
 			MatrixUser:        getEnv("MATRIX_USER", ""),
 			MatrixPassword:    getEnv("MATRIX_PASSWORD", ""),
 			MatrixAccessToken: getEnv("MATRIX_ACCESS_TOKEN", ""),
+			LotMethod:         getEnv("LOT_METHOD", "fifo"),
+			BaseCurrency:      getEnv("BASE_CURRENCY", "EUR"),
+			StockAPIURL:       getEnv("STOCKAPI_URL", "http://stockapi:4000"),
+			RiskFreeRate:      getEnv("RISK_FREE_RATE", "0"),
+			FetchDividends:    getEnv("FETCH_DIVIDENDS", "false"),
+			SessionTTL:        getEnv("SESSION_TTL", "720h"),
+			CORSOrigins:       getEnv("CORS_ALLOWED_ORIGINS", "*"),
+			CORSMethods:       getEnv("CORS_ALLOWED_METHODS", "GET, POST, PUT, PATCH, DELETE, OPTIONS"),
+			CORSHeaders:       getEnv("CORS_ALLOWED_HEADERS", "Content-Type, Authorization, X-API-Key"),
+			CORSCredentials:   getEnv("CORS_ALLOW_CREDENTIALS", "false"),
+			CORSMaxAge:        getEnv("CORS_MAX_AGE", "600"),
+			Notifier:          getEnv("NOTIFIER", ""),
 		}
 	})
 	return config
@@ -0,0 +1,102 @@
+package migrate
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jagac/pfinance/internal/repositories/migrations"
+)
+
+func TestNew(t *testing.T) {
+	file := func(body string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(body)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []Migration
+		wantErr string
+	}{
+		{
+			name: "pairs up and down scripts in version order",
+			files: fstest.MapFS{
+				"0002_add_index.up.sql":       file("CREATE INDEX"),
+				"0001_create_assets.up.sql":   file("CREATE TABLE"),
+				"0001_create_assets.down.sql": file("DROP TABLE"),
+				"0010_add_column.up.sql":      file("ALTER TABLE"),
+			},
+			want: []Migration{
+				{Version: 1, Name: "create_assets", Up: "CREATE TABLE", Down: "DROP TABLE"},
+				{Version: 2, Name: "add_index", Up: "CREATE INDEX"},
+				{Version: 10, Name: "add_column", Up: "ALTER TABLE"},
+			},
+		},
+		{
+			name: "ignores files that are not migrations",
+			files: fstest.MapFS{
+				"0001_create_assets.up.sql":  file("CREATE TABLE"),
+				"migrations.go":              file("package migrations"),
+				"README.md":                  file("notes"),
+				"0002_missing_direction.sql": file("SELECT 1"),
+			},
+			want: []Migration{{Version: 1, Name: "create_assets", Up: "CREATE TABLE"}},
+		},
+		{
+			name: "rejects a down script without an up script",
+			files: fstest.MapFS{
+				"0001_create_assets.down.sql": file("DROP TABLE"),
+			},
+			wantErr: "migration 1_create_assets has no up script",
+		},
+		{
+			name: "rejects two names for one version",
+			files: fstest.MapFS{
+				"0001_create_assets.up.sql": file("CREATE TABLE"),
+				"0001_create_prices.up.sql": file("CREATE TABLE"),
+			},
+			wantErr: "migration version 1 is used by both",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(nil, nil, tt.files)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("New() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			if len(m.migrations) != len(tt.want) {
+				t.Fatalf("New() read %d migrations, want %d", len(m.migrations), len(tt.want))
+			}
+			for i, got := range m.migrations {
+				if got != tt.want[i] {
+					t.Errorf("migration %d = %+v, want %+v", i, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestEmbeddedMigrations checks the migrations shipped with the service are numbered without
+// gaps and can all be reverted.
+func TestEmbeddedMigrations(t *testing.T) {
+	m, err := New(nil, nil, migrations.FS)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i, migration := range m.migrations {
+		if migration.Version != i+1 {
+			t.Errorf("migration %d_%s follows version %d", migration.Version, migration.Name, i)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %d_%s has no down script", migration.Version, migration.Name)
+		}
+	}
+}
@@ -19,6 +19,7 @@ app.get('/stock/:symbol', async (req, res) => {
         res.json({
             symbol,
             price: price,
+            currency: quote.currency,
             timestamp: new Date(),
         });
     } catch (err) {
@@ -26,6 +27,62 @@ app.get('/stock/:symbol', async (req, res) => {
     }
 });
 
+// Route to get daily closes of a stock between two dates (YYYY-MM-DD)
+app.get('/stock/:symbol/history', async (req, res) => {
+    const symbol = req.params.symbol.toUpperCase();
+    const { from, to } = req.query;
+
+    if (!from) {
+        return res.status(400).json({ error: 'from date is required' });
+    }
+
+    try {
+        const chart = await yahooFinance.chart(symbol, {
+            period1: from,
+            period2: to || new Date(),
+            interval: '1d',
+        });
+
+        res.json({
+            symbol,
+            currency: chart.meta.currency,
+            prices: chart.quotes
+                .filter((quote) => quote.close != null)
+                .map((quote) => ({ date: quote.date, close: quote.close })),
+        });
+    } catch (err) {
+        res.status(500).json({ error: 'Failed to retrieve stock history', details: err.message });
+    }
+});
+
+// Route to get the dividends a stock paid between two dates (YYYY-MM-DD), keyed by ex-date
+app.get('/stock/:symbol/dividends', async (req, res) => {
+    const symbol = req.params.symbol.toUpperCase();
+    const { from, to } = req.query;
+
+    if (!from) {
+        return res.status(400).json({ error: 'from date is required' });
+    }
+
+    try {
+        const chart = await yahooFinance.chart(symbol, {
+            period1: from,
+            period2: to || new Date(),
+            interval: '1d',
+            events: 'div',
+        });
+
+        res.json({
+            symbol,
+            currency: chart.meta.currency,
+            dividends: (chart.events?.dividends ?? [])
+                .map((dividend) => ({ date: dividend.date, amount: dividend.amount })),
+        });
+    } catch (err) {
+        res.status(500).json({ error: 'Failed to retrieve stock dividends', details: err.message });
+    }
+});
+
 app.listen(port, () => {
     console.log(`Stock price service is running on http://localhost:${port}`);
 });